@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	hf "github.com/hupe1980/go-huggingface"
+)
+
+// Mode yang didukung oleh flag -mode:
+//   - qa: tanya jawab atas tabel dengan model TAPAS melalui ConnectAIModel
+//   - summarize: tabel diratakan menjadi teks lalu diringkas
+//   - classify: teks yang diketik pengguna diklasifikasikan (misal sentimen)
+//   - extractive-qa: jawaban dicari sebagai potongan teks dari paragraf konteks
+//   - translate: teks yang diketik pengguna diterjemahkan dari -src ke -tgt
+const (
+	modeQA           = "qa"
+	modeSummarize    = "summarize"
+	modeClassify     = "classify"
+	modeExtractiveQA = "extractive-qa"
+	modeTranslate    = "translate"
+)
+
+// modes berisi semua mode yang valid, sesuai urutan di pesan bantuan.
+var modes = []string{modeQA, modeSummarize, modeClassify, modeExtractiveQA, modeTranslate}
+
+// validMode melaporkan apakah mode termasuk salah satu mode yang didukung.
+func validMode(mode string) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// usesTable melaporkan apakah mode memakai isi tabel. Mode classify dan
+// translate hanya memakai teks yang diketik pengguna.
+func usesTable(mode string) bool {
+	return mode != modeClassify && mode != modeTranslate
+}
+
+// app menyimpan state satu sesi CLI: tabel yang sudah dimuat dan client yang
+// dipakai untuk menjawab setiap pertanyaan.
+type app struct {
+	mode  string
+	table tableqa.Table
+	// types adalah tipe setiap kolom hasil tableqa.InferColumnTypes
+	types map[string]string
+	// instruction ditambahkan di awal teks pada mode summarize (-instruction)
+	instruction string
+	// qaContext adalah paragraf yang dicari jawabannya pada mode extractive-qa
+	qaContext string
+	connector *tableqa.AIModelConnector
+	// chunkSize, jika > 0, memecah tabel per chunkSize baris pada mode qa (-chunk-size)
+	chunkSize int
+	// normalizeNumbers membersihkan angka berformat seperti "$1,234.00"
+	// sebelum tabel dikirim pada mode qa (-normalize-numbers)
+	normalizeNumbers bool
+	// precision adalah jumlah angka di belakang koma untuk jawaban SUM dan
+	// AVERAGE pada mode qa (-precision); negatif berarti tidak dibulatkan
+	precision int
+	// minScore menandai jawaban qa dengan score di bawahnya sebagai
+	// "low confidence" (-min-score); nol berarti tidak pernah
+	minScore float64
+	// srcLang dan tgtLang adalah bahasa asal dan tujuan pada mode translate (-src, -tgt)
+	srcLang, tgtLang string
+	hf               *hf.InferenceClient
+	token            string
+	// format adalah format output jawaban, formatText atau formatJSON
+	format string
+	// pretty membuat jawaban JSON ditulis dengan indentasi (-pretty)
+	pretty bool
+	// maxCells membatasi jumlah sel yang dicetak dalam format teks (-max-cells); 0 berarti semua
+	maxCells int
+	// template, jika diisi, dipakai untuk menulis setiap jawaban alih-alih format (-template)
+	template *template.Template
+	// results, jika diisi, menerima setiap pertanyaan dan jawabannya (-out)
+	results *resultWriter
+	// progress, jika diisi, dipanggil setelah setiap pertanyaan selesai (-progress)
+	progress tableqa.ProgressFunc
+	logger   *log.Logger
+}
+
+// answer menjawab satu pertanyaan sesuai mode yang dipilih.
+func (a *app) answer(ctx context.Context, query string) (fmt.Stringer, error) {
+	switch a.mode {
+	case modeQA:
+		a.checkQuery(query)
+		data := a.qaData()
+		var response tableqa.Response
+		var err error
+		if a.chunkSize > 0 {
+			response, err = a.connector.ConnectAIModelChunked(ctx, data, query, a.token, a.chunkSize)
+		} else {
+			response, err = a.connector.ConnectAIModel(ctx, Inputs{Table: data, Query: query}, a.token)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return a.finish(response), nil
+	case modeSummarize:
+		return a.summarize(ctx, query)
+	case modeClassify:
+		// Pada mode classify, teks yang diketik pengguna yang diklasifikasikan
+		a.logger.Printf("Classifying %d bytes of text", len(query))
+		return tableqa.ClassifyText(ctx, a.hf, query)
+	case modeExtractiveQA:
+		a.logger.Printf("Searching %d bytes of context for the answer", len(a.qaContext))
+		return tableqa.AnswerQuestion(ctx, a.hf, tableqa.QAInputs{Question: query, Context: a.qaContext})
+	case modeTranslate:
+		a.logger.Printf("Translating %d bytes of text from %s to %s", len(query), a.srcLang, a.tgtLang)
+		text, err := tableqa.Translate(ctx, a.hf, query, a.srcLang, a.tgtLang)
+		if err != nil {
+			return nil, err
+		}
+		return Response{Answer: text}, nil
+	default:
+		return nil, fmt.Errorf("unknown mode %q (want one of %s)", a.mode, strings.Join(modes, ", "))
+	}
+}
+
+// checkQuery menulis ke log verbose kolom yang disebut query, agar pengguna
+// bisa memperbaiki kalimatnya jika model memilih kolom yang salah, dan
+// memperingatkan jika pertanyaan angka menyebut kolom berisi teks.
+func (a *app) checkQuery(query string) {
+	if matched := tableqa.MatchColumns(query, a.table.Columns); len(matched) > 0 {
+		a.logger.Printf("Query mentions columns: %s", strings.Join(matched, ", "))
+	} else {
+		a.logger.Printf("Query does not mention any column (available: %s)", strings.Join(a.table.Columns, ", "))
+	}
+	for _, column := range textColumnsInNumericQuery(query, a.table.Columns, a.types) {
+		log.Printf("Warning: column %q contains text, so a numeric answer about it may be wrong", column)
+	}
+}
+
+// qaData mengembalikan isi tabel yang dikirim ke TAPAS. Angka berformat hanya
+// dibersihkan di payload; a.table tetap berisi nilai asli untuk ditampilkan.
+func (a *app) qaData() map[string][]string {
+	if a.normalizeNumbers {
+		return tableqa.NormalizeNumbers(a.table.Data)
+	}
+	return a.table.Data
+}
+
+// finish membulatkan jawaban qa sesuai -precision dan menandai jawaban yang
+// kurang diyakini model (-min-score) agar pengguna tidak langsung
+// mempercayainya.
+func (a *app) finish(response tableqa.Response) tableqa.Response {
+	response = response.Round(a.precision)
+	if response.LowConfidence(a.minScore) {
+		response.Answer = fmt.Sprintf("low confidence (score %.2f): %s", response.Score, response.Answer)
+	}
+	return response
+}
+
+// summarize meratakan tabel menjadi teks, diawali query pengguna, lalu
+// mengirimnya ke endpoint summarization.
+func (a *app) summarize(ctx context.Context, query string) (fmt.Stringer, error) {
+	text := summaryInput(a.instruction, query, a.table)
+
+	a.logger.Printf("Sending %d bytes to the summarization endpoint", len(text))
+
+	summary, err := tableqa.Summarize(ctx, a.hf, text)
+	if err != nil {
+		return nil, fmt.Errorf("summarizing text: %w", err)
+	}
+	return Response{Answer: summary}, nil
+}
+
+// summaryInput menyusun teks yang dikirim ke endpoint summarization: instruction
+// (jika ada), teks yang diketik pengguna, lalu tabel yang diratakan. Instruksi
+// hanya dipakai untuk model teks, tidak pernah untuk TAPAS.
+func summaryInput(instruction, query string, t tableqa.Table) string {
+	text := query + "\n" + tableqa.FlattenTable(t)
+	if instruction = strings.TrimSpace(instruction); instruction != "" {
+		text = instruction + "\n" + text
+	}
+	return text
+}
+
+// numericWords adalah kata yang menandakan pertanyaan meminta hasil hitungan.
+var numericWords = []string{"sum", "total", "average", "mean", "max", "min", "highest", "lowest", "how much", "how many"}
+
+// textColumnsInNumericQuery mengembalikan kolom bertipe teks yang disebut di
+// query ketika query meminta hasil hitungan, misal "total Appliance". Nama
+// kolom dicocokkan dengan tableqa.MatchColumns. Urutan hasil mengikuti columns.
+func textColumnsInNumericQuery(query string, columns []string, types map[string]string) []string {
+	// Cocokkan kata utuh agar "min" tidak cocok dengan "minute" dan "sum"
+	// tidak cocok dengan "summary": query dipecah menjadi kata lalu diapit
+	// spasi, sehingga frasa seperti "how many" tetap bisa dicari
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	padded := " " + strings.Join(words, " ") + " "
+
+	numeric := false
+	for _, word := range numericWords {
+		if strings.Contains(padded, " "+word+" ") {
+			numeric = true
+			break
+		}
+	}
+	if !numeric {
+		return nil
+	}
+
+	var found []string
+	for _, column := range tableqa.MatchColumns(query, columns) {
+		if !tableqa.IsNumericType(types[column]) {
+			found = append(found, column)
+		}
+	}
+	return found
+}
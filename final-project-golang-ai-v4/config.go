@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config berisi pengaturan yang bisa disimpan di file -config. Nama field di
+// file sama dengan nama flag-nya; field yang kosong tidak mengubah default.
+// File CSV dan pertanyaan sengaja tidak termasuk karena berubah di setiap
+// pemanggilan.
+type Config struct {
+	Mode      string `json:"mode" yaml:"mode"`
+	Format    string `json:"format" yaml:"format"`
+	Model     string `json:"model" yaml:"model"`
+	Timeout   string `json:"timeout" yaml:"timeout"`
+	RateLimit int    `json:"rate-limit" yaml:"rate-limit"`
+	Proxy     string `json:"proxy" yaml:"proxy"`
+	CACert    string `json:"cacert" yaml:"cacert"`
+	Delimiter string `json:"delimiter" yaml:"delimiter"`
+	Columns   string `json:"columns" yaml:"columns"`
+	// Precision berupa pointer karena 0 adalah nilai yang valid
+	Precision *int `json:"precision" yaml:"precision"`
+	Verbose   bool `json:"verbose" yaml:"verbose"`
+}
+
+// LoadConfig membaca Config dari file JSON atau YAML sesuai ekstensinya
+// (.json, .yaml atau .yml). Field yang tidak dikenal ditolak agar salah ketik
+// tidak diam-diam diabaikan.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var cfg Config
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&cfg)
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(data, &cfg)
+	default:
+		return Config{}, fmt.Errorf("unsupported config file extension %q (want .json, .yaml or .yml)", ext)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// values mengembalikan nilai setiap field yang diisi, dengan key nama flag.
+func (c Config) values() map[string]string {
+	values := map[string]string{
+		"mode":      c.Mode,
+		"format":    c.Format,
+		"model":     c.Model,
+		"timeout":   c.Timeout,
+		"proxy":     c.Proxy,
+		"cacert":    c.CACert,
+		"delimiter": c.Delimiter,
+		"columns":   c.Columns,
+	}
+	if c.RateLimit != 0 {
+		values["rate-limit"] = strconv.Itoa(c.RateLimit)
+	}
+	if c.Precision != nil {
+		values["precision"] = strconv.Itoa(*c.Precision)
+	}
+	if c.Verbose {
+		values["verbose"] = "true"
+	}
+	for name, value := range values {
+		if value == "" {
+			delete(values, name)
+		}
+	}
+	return values
+}
+
+// applyTo menyalin nilai c ke flag di fs yang tidak diberikan secara
+// eksplisit di command line, sehingga flag selalu menang atas file config.
+func (c Config) applyTo(fs *flag.FlagSet) error {
+	set := visitedFlags(fs)
+	for name, value := range c.values() {
+		if set[name] {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("invalid %s in config: %w", name, err)
+		}
+	}
+	return nil
+}
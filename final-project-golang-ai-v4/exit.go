@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+
+	"a21hc3NpZ25tZW50/tableqa"
+)
+
+// Exit code program, agar skrip bisa membedakan jenis kegagalan.
+const (
+	// exitFailure dipakai untuk error lain, termasuk pertanyaan yang gagal dijawab
+	exitFailure = 1
+	// exitConfig dipakai untuk flag, file env, atau token yang tidak diset;
+	// sama dengan exit code package flag untuk flag yang tidak dikenal
+	exitConfig = 2
+	// exitIO dipakai ketika file CSV atau file hasil tidak bisa dibaca/ditulis
+	exitIO = 3
+	// exitNetwork dipakai ketika Hugging Face tidak bisa dihubungi atau
+	// membalas dengan error server
+	exitNetwork = 4
+	// exitAuth dipakai ketika token ditolak oleh Hugging Face
+	exitAuth = 5
+	// exitInterrupted dipakai ketika program dihentikan dengan Ctrl-C,
+	// mengikuti konvensi shell 128 + SIGINT
+	exitInterrupted = 130
+)
+
+// exitError menandai err dengan exit code tertentu, untuk error yang jenisnya
+// tidak bisa ditebak dari tipenya, misal flag yang tidak valid.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// configErrorf membuat error konfigurasi yang berakhir dengan exitConfig.
+func configErrorf(format string, args ...interface{}) error {
+	return &exitError{code: exitConfig, err: fmt.Errorf(format, args...)}
+}
+
+// ioErrorf membuat error baca/tulis file yang berakhir dengan exitIO.
+func ioErrorf(format string, args ...interface{}) error {
+	return &exitError{code: exitIO, err: fmt.Errorf(format, args...)}
+}
+
+// exitCode memetakan error dari run ke exit code program.
+func exitCode(err error) int {
+	var (
+		coded   *exitError
+		apiErr  *tableqa.APIError
+		pathErr *fs.PathError
+		netErr  net.Error
+	)
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.Is(err, tableqa.ErrInvalidToken), errors.Is(err, tableqa.ErrUnauthorized):
+		return exitAuth
+	case errors.As(err, &apiErr):
+		switch {
+		case apiErr.StatusCode >= http.StatusInternalServerError:
+			return exitNetwork
+		default:
+			return exitFailure
+		}
+	case errors.As(err, &pathErr):
+		return exitIO
+	case errors.As(err, &netErr), errors.Is(err, context.DeadlineExceeded):
+		return exitNetwork
+	default:
+		return exitFailure
+	}
+}
+
+// describeOpenError mengubah error saat membuka file CSV di path menjadi error
+// dengan pesan yang menjelaskan cara memperbaikinya. File yang tidak ada
+// mendapat pesan tersendiri, karena ini yang paling sering terjadi saat
+// pertama kali menjalankan program tanpa data contoh.
+func describeOpenError(path string, err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return ioErrorf("CSV file not found: %s. Use -file to specify a different path.", path)
+	case errors.Is(err, fs.ErrPermission):
+		return ioErrorf("Permission denied reading CSV file: %s. Check the file permissions or use -file to specify a different path.", path)
+	default:
+		return ioErrorf("failed to open CSV file %q (set it with -file or as the first argument): %w", path, err)
+	}
+}
@@ -3,15 +3,16 @@ module a21hc3NpZ25tZW50
 go 1.18
 
 require (
+	github.com/hupe1980/go-huggingface v0.0.15
 	github.com/joho/godotenv v1.5.1
 	github.com/onsi/ginkgo/v2 v2.1.4
 	github.com/onsi/gomega v1.19.0
+	golang.org/x/text v0.3.7
+	golang.org/x/time v0.5.0
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 require (
-	github.com/hupe1980/go-huggingface v0.0.15 // indirect
 	golang.org/x/net v0.0.0-20220225172249-27dd8689420f // indirect
 	golang.org/x/sys v0.0.0-20220319134239-a9b59b0215f8 // indirect
-	golang.org/x/text v0.3.7 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
@@ -3,201 +3,881 @@ package main
 import (
 	"bytes"
 	"context"
-	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"a21hc3NpZ25tZW50/tableqa"
 
 	hf "github.com/hupe1980/go-huggingface"
 	"github.com/joho/godotenv"
 )
 
-type AIModelConnector struct {
-	Client *http.Client
+// Tipe berikut diteruskan dari package tableqa agar kode yang sudah memakai
+// package main (termasuk test) tetap berjalan tanpa perubahan.
+type (
+	AIModelConnector = tableqa.AIModelConnector
+	Inputs           = tableqa.Inputs
+	Response         = tableqa.Response
+)
+
+// CsvToSlice meneruskan ke tableqa.CsvToSlice.
+func CsvToSlice(data string) (map[string][]string, error) {
+	return tableqa.CsvToSlice(data)
 }
 
-type Inputs struct {
-	Table map[string][]string `json:"table"`
-	Query string              `json:"query"`
+// defaultCSVFile adalah file CSV yang dibaca ketika tidak ada path yang diberikan.
+const defaultCSVFile = "data-series.csv"
+
+// stdinPath adalah nilai -file yang berarti "baca CSV dari standard input".
+const stdinPath = "-"
+
+// showTableRows adalah jumlah baris yang dicetak oleh -show-table.
+const showTableRows = 10
+
+// cliFlags berisi nama flag yang diberikan di command line. Dicatat tepat
+// setelah flag.Parse, sebelum -config mengisi flag lain lewat Set, karena
+// flag.Visit juga melaporkan flag yang diisi dari file config.
+var cliFlags map[string]bool
+
+// visitedFlags mengembalikan nama flag di fs yang sudah diset.
+func visitedFlags(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
 }
 
-type Response struct {
-	Answer      string   `json:"answer"`
-	Coordinates [][]int  `json:"coordinates"`
-	Cells       []string `json:"cells"`
-	Aggregator  string   `json:"aggregator"`
+// isFlagSet melaporkan apakah flag dengan nama tersebut diberikan secara
+// eksplisit di command line. Nilai dari file config tidak dihitung.
+func isFlagSet(name string) bool {
+	return cliFlags[name]
 }
 
-func CsvToSlice(data string) (map[string][]string, error) {
-	// Membuat pembaca CSV dari string data yang diberikan
-	reader := csv.NewReader(strings.NewReader(data))
+// resolveModel memilih model untuk mode qa: nilai flag -model jika diberikan,
+// lalu variabel lingkungan HUGGINGFACE_MODEL, lalu tableqa.DefaultModel.
+func resolveModel(flagValue, envValue string) string {
+	if flagValue = strings.TrimSpace(flagValue); flagValue != "" {
+		return flagValue
+	}
+	if envValue = strings.TrimSpace(envValue); envValue != "" {
+		return envValue
+	}
+	return tableqa.DefaultModel
+}
 
-	// Membaca semua baris dari data CSV
-	lines, err := reader.ReadAll()
-	if err != nil {
-		return nil, err
+// delimiterFor menentukan pemisah kolom untuk file name: override dari
+// -delimiter jika diisi ("tab" atau "\t" berarti tab), selain itu '\t' untuk
+// file .tsv dan ',' untuk file lainnya.
+func delimiterFor(name, override string) (rune, error) {
+	switch override {
+	case "":
+		if strings.EqualFold(filepath.Ext(name), ".tsv") {
+			return '\t', nil
+		}
+		return ',', nil
+	case "tab", `\t`:
+		return '\t', nil
+	}
+
+	runes := []rune(override)
+	if len(runes) != 1 {
+		return 0, fmt.Errorf("delimiter must be a single character or \"tab\", got %q", override)
 	}
+	return runes[0], nil
+}
+
+// progressPrinter mengembalikan tableqa.ProgressFunc yang menulis
+// "processed 12/50" ke w, atau "processed 12" jika total tidak diketahui (0).
+func progressPrinter(w io.Writer) tableqa.ProgressFunc {
+	return func(done, total int) {
+		if total > 0 {
+			fmt.Fprintf(w, "processed %d/%d\n", done, total)
+			return
+		}
+		fmt.Fprintf(w, "processed %d\n", done)
+	}
+}
 
-	// Inisialisasi peta hasil dengan kunci string dan nilai slice string
-	result := make(map[string][]string)
-	if len(lines) == 0 {
-		// Jika tidak ada baris dalam data CSV, kembalikan peta kosong
-		return result, nil
+// parseTimeout membaca nilai -timeout, misal "60s" atau "2m", dan memastikan
+// durasinya positif.
+func parseTimeout(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("timeout must be positive, got %s", s)
 	}
+	return d, nil
+}
 
-	// Mengambil baris pertama sebagai header
-	headers := lines[0]
-	for _, header := range headers {
-		// Inisialisasi setiap header dengan slice kosong dalam peta hasil
-		result[header] = []string{}
+// splitList memecah daftar yang dipisahkan koma, misal nilai -columns, dan
+// membuang spasi di sekitar setiap item serta item yang kosong.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
+}
 
-	// Iterasi melalui baris data (mengabaikan baris pertama yang merupakan header)
-	for _, line := range lines[1:] {
-		for i, value := range line {
-			// Menambahkan nilai ke dalam slice yang sesuai dengan header
-			result[headers[i]] = append(result[headers[i]], value)
+// parseFilters mengubah nilai -filter seperti "Region=EU,Room=Kitchen"
+// menjadi map kolom ke nilai untuk tableqa.ApplyFilters.
+func parseFilters(s string) (map[string]string, error) {
+	filters := make(map[string]string)
+	for _, item := range splitList(s) {
+		column, value, ok := strings.Cut(item, "=")
+		column = strings.TrimSpace(column)
+		if !ok || column == "" {
+			return nil, fmt.Errorf("filter %q is not in column=value form", item)
 		}
+		filters[column] = strings.TrimSpace(value)
 	}
+	return filters, nil
+}
 
-	// Mengembalikan hasil peta dan nil (tidak ada error)
-	return result, nil
+// parseRenames mengubah nilai -rename seperti "col_1=Name,Total_Sales_USD=Sales"
+// menjadi map nama kolom lama ke nama baru untuk tableqa.RenameTableColumns.
+func parseRenames(s string) (map[string]string, error) {
+	renames := make(map[string]string)
+	for _, item := range splitList(s) {
+		old, name, ok := strings.Cut(item, "=")
+		old, name = strings.TrimSpace(old), strings.TrimSpace(name)
+		if !ok || old == "" || name == "" {
+			return nil, fmt.Errorf("rename %q is not in old=new form", item)
+		}
+		renames[old] = name
+	}
+	return renames, nil
 }
 
-func (c *AIModelConnector) ConnectAIModel(payload interface{}, token string) (Response, error) {
-	// Coba konversi payload ke tipe Inputs
-	inputs, ok := payload.(Inputs)
-	if !ok {
-		// Jika gagal, kembalikan error karena tipe payload tidak valid
-		return Response{}, errors.New("invalid payload type")
+// resolveKeys mengganti setiap key m, yaitu nama kolom dari -rename atau
+// -filter, dengan nama kolom seperti yang tertulis di header lewat
+// tableqa.ResolveColumn. Dua key yang menunjuk kolom yang sama, misal
+// "region" dan "REGION", ditolak agar nilainya tidak saling menimpa.
+func resolveKeys(columns []string, m map[string]string, caseInsensitive bool) (map[string]string, error) {
+	resolved := make(map[string]string, len(m))
+	keys := make(map[string]string, len(m))
+	for name, value := range m {
+		column, err := tableqa.ResolveColumn(columns, name, caseInsensitive)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := keys[column]; ok {
+			// Urutkan agar pesan error tidak bergantung pada urutan map
+			if other > name {
+				other, name = name, other
+			}
+			return nil, fmt.Errorf("%q and %q both refer to column %q", other, name, column)
+		}
+		keys[column] = name
+		resolved[column] = value
 	}
+	return resolved, nil
+}
 
-	// Serialize inputs menjadi JSON
-	reqBody, err := json.Marshal(inputs)
+// readTokenFile membaca token dari file di path, membuang spasi dan baris
+// baru di awal dan akhir. File kosong dianggap error agar tidak dikirim
+// sebagai token kosong.
+func readTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		// Jika terjadi error saat serialisasi, kembalikan error
-		return Response{}, err
+		return "", err
 	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
 
-	// Buat permintaan HTTP POST ke URL API
-	req, err := http.NewRequest("POST", "https://api-inference.huggingface.co/models/openai-community/gpt2", bytes.NewBuffer(reqBody))
-	if err != nil {
-		// Jika terjadi error saat membuat permintaan, kembalikan error
-		return Response{}, err
+// defaultEnvFile adalah file .env yang dimuat ketika -env tidak diberikan.
+const defaultEnvFile = ".env"
+
+// loadEnv memuat variabel dari file env di path tanpa menimpa variabel yang
+// sudah ada di environment. File yang tidak ada diabaikan kecuali required,
+// sehingga token bisa diberikan langsung lewat environment (misal di container).
+func loadEnv(path string, required bool) error {
+	err := godotenv.Load(path)
+	if err != nil && !required && errors.Is(err, fs.ErrNotExist) {
+		return nil
 	}
+	return err
+}
 
-	// Set header Authorization dengan token yang diberikan
-	req.Header.Set("Authorization", "Bearer "+token)
-	// Set header Content-Type sebagai application/json
-	req.Header.Set("Content-Type", "application/json")
+// Format output yang didukung oleh flag -format.
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
 
-	// Kirim permintaan HTTP menggunakan client
-	resp, err := c.Client.Do(req)
-	if err != nil {
-		// Jika terjadi error saat mengirim permintaan, kembalikan error
-		return Response{}, err
+// writeResponse menulis r ke w sesuai format: teks yang mudah dibaca, atau
+// JSON lengkap (misal termasuk coordinates dan cells) untuk diproses program lain.
+// JSON ditulis dalam satu baris kecuali pretty diset. Jika maxCells > 0,
+// format teks hanya menampilkan maxCells sel pertama jawaban tabel; JSON
+// selalu berisi semua sel.
+func writeResponse(w io.Writer, r fmt.Stringer, format string, pretty bool, maxCells int) error {
+	switch format {
+	case formatText:
+		if response, ok := r.(Response); ok {
+			_, err := fmt.Fprintln(w, response.Format(maxCells))
+			return err
+		}
+		_, err := fmt.Fprintln(w, r)
+		return err
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		if pretty {
+			enc.SetIndent("", "  ")
+		}
+		return enc.Encode(r)
+	default:
+		return fmt.Errorf("unknown output format %q (want %q or %q)", format, formatText, formatJSON)
 	}
-	// Pastikan untuk menutup body respons setelah selesai
-	defer resp.Body.Close()
+}
 
-	// Periksa status kode respons, jika tidak OK, kembalikan error
-	if resp.StatusCode != http.StatusOK {
-		return Response{}, fmt.Errorf("failed to connect to AI model with status: %d", resp.StatusCode)
+// parseTemplate mem-parse nilai -template, misal
+// "The answer is {{.Answer}} ({{.Aggregator}})", sehingga kesalahan sintaks
+// dilaporkan sebelum pertanyaan pertama dikirim.
+func parseTemplate(text string) (*template.Template, error) {
+	return template.New("output").Parse(text)
+}
+
+// writeTemplate menulis r ke w dengan tmpl, diakhiri baris baru. Template
+// dijalankan ke buffer lebih dulu agar output tidak terpotong jika gagal,
+// misal karena field yang tidak ada pada jawaban mode tersebut.
+func writeTemplate(w io.Writer, r fmt.Stringer, tmpl *template.Template) error {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, r); err != nil {
+		return err
+	}
+	if !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+		b.WriteByte('\n')
 	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
 
-	// Decode body respons JSON ke dalam struct Response
-	var result Response
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		// Jika terjadi error saat decoding, kembalikan error
-		return Response{}, err
+// exportTable menulis t ke file CSV di path (-export). File yang sudah ada
+// ditimpa.
+func exportTable(path string, t tableqa.Table) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := tableqa.WriteCSV(file, t); err != nil {
+		file.Close()
+		return err
 	}
+	return file.Close()
+}
 
-	// Kembalikan hasil decoding sebagai Response dan nil untuk error
-	return result, nil
+// writeModels menulis daftar model ke w: tabel ID, task, dan deskripsi yang
+// rata kolom untuk format teks, atau array JSON.
+func writeModels(w io.Writer, models []tableqa.ModelInfo, format string) error {
+	switch format {
+	case formatText:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tTASK\tDESCRIPTION")
+		for _, m := range models {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", m.ID, m.Task, m.Description)
+		}
+		return tw.Flush()
+	case formatJSON:
+		return json.NewEncoder(w).Encode(models)
+	default:
+		return fmt.Errorf("unknown output format %q (want %q or %q)", format, formatText, formatJSON)
+	}
 }
 
 func main() {
-	// Buka file CSV dengan nama "data-series.csv"
-	file, err := os.Open("data-series.csv")
-	if err != nil {
-		// Jika terjadi error saat membuka file, log error dan hentikan program
-		log.Fatalf("Failed to open file: %v", err)
+	err := run()
+	switch code := exitCode(err); code {
+	case 0:
+		return
+	case exitInterrupted:
+		fmt.Fprintln(os.Stderr, "Cancelled")
+		os.Exit(code)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(code)
+	}
+}
+
+// run menjalankan CLI dan mengembalikan error yang dipetakan ke exit code
+// oleh exitCode. Semua defer di sini tetap dijalankan sebelum program keluar.
+func run() error {
+	// Path file CSV bisa diberikan lewat flag -file atau argumen posisi pertama
+	configPath := flag.String("config", "", "JSON or YAML file with default flag values, e.g. model and timeout; flags on the command line take precedence")
+	filePath := flag.String("file", defaultCSVFile, "path to the CSV file to query, or - to read it from stdin (may also be given as the first argument)")
+	verbose := flag.Bool("verbose", false, "log what the program is doing to stderr")
+	format := flag.String("format", formatText, "output format: text or json")
+	templateText := flag.String("template", "", "Go text/template for each answer instead of -format, e.g. \"The answer is {{.Answer}} ({{.Aggregator}})\"")
+	maxCells := flag.Int("max-cells", 0, "in text format, print at most this many cells of each answer followed by \"...and N more\" (0 prints all; JSON output always has every cell)")
+	pretty := flag.Bool("pretty", false, "indent JSON answers with two spaces (requires -format json)")
+	mode := flag.String("mode", modeQA, "what to do with each query: qa (ask the table with TAPAS), summarize (summarize the table as text), classify (classify the query text), extractive-qa (find the answer in a text passage) or translate (translate the query text from -src to -tgt)")
+	instruction := flag.String("instruction", "", "in summarize mode, text to put before the input, e.g. \"Summarize in one sentence:\"")
+	srcLang := flag.String("src", "en", "in translate mode, language code to translate from, e.g. en")
+	tgtLang := flag.String("tgt", "id", "in translate mode, language code to translate to, e.g. id")
+	contextPath := flag.String("context", "", "text file to search for answers in extractive-qa mode (default the table flattened to text)")
+	tokenFile := flag.String("token-file", "", "read the Hugging Face token from this file if HUGGINGFACE_TOKEN is not set, e.g. a mounted secret")
+	envPath := flag.String("env", defaultEnvFile, "path to a .env file with HUGGINGFACE_TOKEN; variables already set in the environment take precedence")
+	outPath := flag.String("out", "", "append each query and its answer to this CSV file (query,answer,aggregator)")
+	rateLimit := flag.Int("rate-limit", 0, "maximum requests per minute sent to the Hugging Face API (0 means no limit)")
+	payloadFormat := flag.String("payload-format", string(tableqa.PayloadColumns), "in qa mode, how the table is sent: columns ({\"col\": [values]}, for TAPAS) or records ([{\"col\": value}], for other endpoints)")
+	revision := flag.String("revision", "", "in qa mode, pin the model to this branch, tag or commit on the Hub for reproducible answers, e.g. main or a commit hash")
+	modelFlag := flag.String("model", "", "Hugging Face model ID for qa mode (default $HUGGINGFACE_MODEL, then "+tableqa.DefaultModel+")")
+	timeoutFlag := flag.String("timeout", tableqa.DefaultTimeout.String(), "how long to wait for each HTTP request, e.g. 60s or 2m; raise it for large models that are slow to start")
+	caCert := flag.String("cacert", "", "PEM file with extra CA certificates to trust, e.g. for a self-hosted inference endpoint")
+	insecure := flag.Bool("insecure", false, "skip TLS certificate verification, e.g. for a local mock server with a self-signed certificate (local development only)")
+	proxy := flag.String("proxy", "", "proxy URL for requests to Hugging Face, e.g. http://proxy:8080 (default $HTTPS_PROXY/$HTTP_PROXY)")
+	query := flag.String("query", "", "answer this query and exit instead of prompting for queries")
+	queriesPath := flag.String("queries", "", "answer every query in this file, one per line (blank lines and lines starting with # are skipped), and exit")
+	csvURL := flag.String("url", "", "fetch the CSV to query from this http(s) URL instead of a local file")
+	encoding := flag.String("encoding", string(tableqa.EncodingUTF8), "character encoding of the CSV files: utf-8 or latin1 (transcoded to UTF-8 before parsing)")
+	tableIndex := flag.Int("table-index", 0, "parse only this table (starting at 0) of a CSV file that contains several tables separated by blank lines, e.g. an export of several worksheets")
+	delimiter := flag.String("delimiter", "", "column separator, e.g. ; or tab (default tab for .tsv files, comma otherwise)")
+	files := flag.String("files", "", "comma-separated CSV files with identical headers to merge and query as one table")
+	rename := flag.String("rename", "", "comma-separated old=new pairs to rename columns before they are sent to the model, e.g. Total_Sales_USD=Sales; -filter and -columns use the new names")
+	filter := flag.String("filter", "", "comma-separated column=value pairs; only rows matching all of them are used, e.g. Region=EU")
+	addRowNumbers := flag.Bool("add-row-numbers", false, "prepend a \"row\" column numbering the rows from 1, which helps the model answer positional questions")
+	caseSensitive := flag.Bool("case-sensitive", false, "match column names in -rename, -filter and -columns exactly instead of ignoring case")
+	columns := flag.String("columns", "", "comma-separated columns to send to the model, in this order (default all columns)")
+	chunkSize := flag.Int("chunk-size", 0, "in qa mode, ask about the table in chunks of this many rows and combine the answers (0 sends the whole table)")
+	precision := flag.Int("precision", tableqa.DefaultPrecision, "in qa mode, round numeric SUM and AVERAGE answers to this many decimal places (-1 keeps the model's answer)")
+	minScore := flag.Float64("min-score", 0, "in qa mode, prefix answers whose score is below this threshold (0 to 1) with \"low confidence\"; answers without a score are printed as usual")
+	normalizeNumbers := flag.Bool("normalize-numbers", false, "in qa mode, strip currency symbols and thousands separators from numeric columns before sending the table, e.g. $1,234.00 becomes 1234.00")
+	progress := flag.Bool("progress", false, "print how many queries have been processed to stderr after each one")
+	count := flag.Bool("count", false, "print the number of columns and rows of the parsed table and exit without querying")
+	export := flag.String("export", "", "write the parsed table, after -rename, -filter and -columns, to this CSV file and exit without querying")
+	showTable := flag.Bool("show-table", false, "print the columns and first rows of the parsed table to stderr before querying")
+	offline := flag.String("offline", "", "answer queries from responses recorded in this directory instead of calling the API (qa mode only)")
+	recordDir := flag.String("record", "", "save each response from the API to this directory for later use with -offline (qa mode only)")
+	dryRun := flag.Bool("dry-run", false, "print the request that would be sent for each query instead of calling the API (qa mode only)")
+	check := flag.Bool("check", false, "check that HUGGINGFACE_TOKEN is valid and exit")
+	listModels := flag.Bool("list-models", false, "print the supported models and exit")
+	flag.Parse()
+	cliFlags = visitedFlags(flag.CommandLine)
+
+	// Nilai dari -config hanya mengisi flag yang tidak diberikan di command line
+	if *configPath != "" {
+		cfg, err := LoadConfig(*configPath)
+		if err != nil {
+			return configErrorf("loading config file: %w", err)
+		}
+		if err := cfg.applyTo(flag.CommandLine); err != nil {
+			return configErrorf("loading config file: %w", err)
+		}
 	}
-	// Pastikan file ditutup setelah selesai digunakan
-	defer file.Close()
 
-	// Buat pembaca CSV untuk membaca file
-	reader := csv.NewReader(file)
-	// Baca semua baris dari file CSV
-	lines, err := reader.ReadAll()
-	if err != nil {
-		// Jika terjadi error saat membaca data CSV, log error dan hentikan program
-		log.Fatalf("Failed to read CSV data: %v", err)
+	if *format != formatText && *format != formatJSON {
+		return configErrorf("unknown output format %q (want %q or %q)", *format, formatText, formatJSON)
 	}
 
-	// Gunakan strings.Builder untuk menggabungkan baris CSV menjadi string
-	var data strings.Builder
-	for _, line := range lines {
-		// Gabungkan setiap baris menjadi string dengan pemisah koma dan tambahkan newline di akhir
-		data.WriteString(strings.Join(line, ",") + "\n")
+	if *minScore < 0 || *minScore > 1 {
+		return configErrorf("invalid -min-score %v: must be between 0 and 1", *minScore)
+	}
+	if *maxCells < 0 {
+		return configErrorf("invalid -max-cells %d: must not be negative", *maxCells)
+	}
+	if *pretty && *format != formatJSON {
+		return configErrorf("-pretty requires -format %s", formatJSON)
+	}
+
+	var tmpl *template.Template
+	if *templateText != "" {
+		if isFlagSet("format") {
+			return configErrorf("-template and -format cannot be used together")
+		}
+		var err error
+		if tmpl, err = parseTemplate(*templateText); err != nil {
+			return configErrorf("invalid -template: %w", err)
+		}
 	}
 
-	// Konversi data CSV menjadi string
-	csvData := data.String()
-	// Panggil fungsi CsvToSlice untuk mengkonversi string CSV menjadi peta
-	result, err := CsvToSlice(csvData)
+	// -list-models tidak membutuhkan CSV maupun token
+	if *listModels {
+		return writeModels(os.Stdout, tableqa.SupportedModels(), *format)
+	}
+	if !validMode(*mode) {
+		return configErrorf("unknown mode %q (want one of %s)", *mode, strings.Join(modes, ", "))
+	}
+	if *dryRun && *mode != modeQA {
+		return configErrorf("-dry-run is only supported in %s mode", modeQA)
+	}
+	timeout, err := parseTimeout(*timeoutFlag)
 	if err != nil {
-		// Jika terjadi error saat konversi CSV, log error dan hentikan program
-		log.Fatalf("Failed to convert CSV to slice: %v", err)
+		return configErrorf("invalid -timeout: %w", err)
+	}
+	if *chunkSize < 0 {
+		return configErrorf("-chunk-size must not be negative")
+	}
+	if *chunkSize > 0 && *mode != modeQA {
+		return configErrorf("-chunk-size is only supported in %s mode", modeQA)
+	}
+	tablePayload, err := tableqa.ParsePayloadFormat(*payloadFormat)
+	if err != nil {
+		return configErrorf("invalid -payload-format: %w", err)
+	}
+	if isFlagSet("payload-format") && *mode != modeQA {
+		return configErrorf("-payload-format is only supported in %s mode", modeQA)
+	}
+	if isFlagSet("revision") {
+		if *mode != modeQA {
+			return configErrorf("-revision is only supported in %s mode", modeQA)
+		}
+		if err := tableqa.ValidateRevision(*revision); err != nil {
+			return configErrorf("invalid -revision: %w", err)
+		}
+	}
+	if *normalizeNumbers && *mode != modeQA {
+		return configErrorf("-normalize-numbers is only supported in %s mode", modeQA)
+	}
+	if *offline != "" && *recordDir != "" {
+		return configErrorf("-offline and -record cannot be used together")
+	}
+	if (*offline != "" || *recordDir != "") && *mode != modeQA {
+		return configErrorf("-offline and -record are only supported in %s mode", modeQA)
+	}
+	// Rekaman dicocokkan dengan teks pertanyaan, sehingga setiap chunk akan
+	// menimpa atau memutar ulang jawaban yang sama
+	if (*offline != "" || *recordDir != "") && *chunkSize > 0 {
+		return configErrorf("-offline and -record cannot be used with -chunk-size")
+	}
+	if *instruction != "" && *mode != modeSummarize {
+		return configErrorf("-instruction is only supported in %s mode", modeSummarize)
+	}
+	if (isFlagSet("src") || isFlagSet("tgt")) && *mode != modeTranslate {
+		return configErrorf("-src and -tgt are only supported in %s mode", modeTranslate)
+	}
+	if *insecure && *caCert != "" {
+		return configErrorf("-insecure and -cacert cannot be used together")
+	}
+	if *export != "" && !usesTable(*mode) {
+		return configErrorf("-export is not supported in %s mode, which does not read a table", *mode)
+	}
+	if *count && !usesTable(*mode) {
+		return configErrorf("-count is not supported in %s mode, which does not read a table", *mode)
+	}
+	if *count && *export != "" {
+		return configErrorf("-count and -export cannot be used together")
+	}
+	if *queriesPath != "" && *query != "" {
+		return configErrorf("-query and -queries cannot be used together")
+	}
+	csvEncoding, err := tableqa.ParseEncoding(*encoding)
+	if err != nil {
+		return configErrorf("invalid -encoding: %w", err)
+	}
+	if isFlagSet("encoding") && *csvURL != "" {
+		return configErrorf("-encoding cannot be used with -url")
+	}
+	if isFlagSet("table-index") && *csvURL != "" {
+		return configErrorf("-table-index cannot be used with -url")
+	}
+	if *contextPath != "" && *mode != modeExtractiveQA {
+		return configErrorf("-context is only supported in %s mode", modeExtractiveQA)
 	}
 
-	// Load variabel lingkungan dari file .env
-	if err := godotenv.Load(); err != nil {
-		// Jika terjadi error saat memuat .env, log error dan hentikan program
-		log.Fatalf("Error loading .env file: %v", err)
+	// Logger verbose hanya menulis ke stderr jika -verbose diset, sehingga
+	// mode normal tetap hanya mencetak jawaban
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	if *verbose {
+		logger.SetOutput(os.Stderr)
 	}
 
-	// Dapatkan nilai token dari variabel lingkungan
+	// Load variabel lingkungan dari file .env jika ada; file yang diberikan
+	// lewat -env wajib ada
+	if err := loadEnv(*envPath, isFlagSet("env")); err != nil {
+		// Jika terjadi error saat memuat .env, hentikan program
+		return configErrorf("loading env file: %w", err)
+	}
+
+	// Dapatkan nilai token dari variabel lingkungan, atau dari -token-file
+	// (misal secret yang di-mount di Kubernetes) jika variabelnya kosong
 	token := os.Getenv("HUGGINGFACE_TOKEN")
-	if token == "" {
-		// Jika token tidak diset di .env, log error dan hentikan program
-		log.Fatal("HUGGINGFACE_TOKEN is required but not set in .env")
+	if *tokenFile != "" {
+		if token != "" {
+			logger.Printf("Ignoring -token-file because HUGGINGFACE_TOKEN is set")
+		} else {
+			var err error
+			if token, err = readTokenFile(*tokenFile); err != nil {
+				return configErrorf("invalid -token-file: %w", err)
+			}
+		}
+	}
+	if token == "" && !*dryRun && *offline == "" && *export == "" && !*count {
+		// Jika token tidak diset di environment, .env, maupun -token-file, hentikan program
+		return configErrorf("HUGGINGFACE_TOKEN is required but not set in the environment, the env file or -token-file")
 	}
 
-	// Buat klien inference baru menggunakan token yang diberikan
-	ic := hf.NewInferenceClient(token)
+	// Model bisa diatur lewat -model atau HUGGINGFACE_MODEL, misal di Docker/CI
+	model := resolveModel(*modelFlag, os.Getenv("HUGGINGFACE_MODEL"))
+	logger.Printf("Using model %s", model)
 
-	// Ambil input query dari pengguna
-	var query string
-	fmt.Print("Can I Help You ? : ")
-	fmt.Scanln(&query)
+	// Client HTTP bersama untuk semua mode, dengan proxy dari -proxy atau environment
+	httpClient, err := tableqa.NewHTTPClient(*proxy)
+	if err != nil {
+		return configErrorf("invalid -proxy: %w", err)
+	}
+	httpClient.Timeout = timeout
+	// Percayai CA internal untuk endpoint self-hosted di balik sertifikat perusahaan
+	if *caCert != "" {
+		pool, err := tableqa.LoadCACerts(*caCert)
+		if err != nil {
+			return configErrorf("invalid -cacert: %w", err)
+		}
+		tableqa.SetRootCAs(httpClient.Transport.(*http.Transport), pool)
+	}
+	// -insecure hanya untuk server tiruan lokal; selalu tampilkan peringatan
+	// meskipun -verbose tidak diset
+	if *insecure {
+		log.Printf("WARNING: -insecure is set, TLS certificates are NOT verified; use this only for local development")
+		tableqa.SetInsecureSkipVerify(httpClient.Transport.(*http.Transport))
+	}
 
-	// Buat struct Inputs dengan data tabel dan query
-	article := Inputs{
-		Table: result,
-		Query: query,
+	// -check hanya memeriksa token lalu keluar, tanpa membaca CSV
+	if *check {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := tableqa.ValidateToken(ctx, httpClient, token); err != nil {
+			return fmt.Errorf("token check failed: %w", err)
+		}
+		fmt.Println("token valid")
+		return nil
 	}
 
-	// Konversi struct Inputs menjadi JSON
-	articleJSON, err := json.Marshal(article)
-	if err != nil {
-		// Jika terjadi error saat mengkonversi ke JSON, log error dan hentikan program
-		log.Fatalf("Error marshaling article to JSON: %v", err)
+	// Daftar file CSV: -files untuk beberapa file sekaligus, atau satu file
+	// dari -file / argumen posisi pertama. Dengan -url tidak ada file lokal.
+	paths := []string{*filePath}
+	if *csvURL != "" {
+		if isFlagSet("file") || *files != "" || flag.NArg() > 0 {
+			return configErrorf("-url cannot be used together with -file, -files or a CSV argument")
+		}
+		paths = nil
+	} else if *files != "" {
+		if isFlagSet("file") {
+			return configErrorf("-file and -files cannot be used together")
+		}
+		paths = splitList(*files)
+		if len(paths) == 0 {
+			return configErrorf("-files needs at least one CSV file")
+		}
+	} else if !isFlagSet("file") && flag.NArg() > 0 {
+		paths = []string{flag.Arg(0)}
+	}
+
+	// Sumber pertanyaan. Secara default pertanyaan dibaca dari stdin.
+	queryInput := os.Stdin
+	tables := make([]tableqa.Table, 0, len(paths))
+	sources := make([]string, 0, len(paths))
+	for _, path := range paths {
+		var csvInput io.Reader
+		if path == stdinPath {
+			if len(paths) > 1 {
+				return configErrorf("-files cannot read from stdin")
+			}
+			csvInput = os.Stdin
+			// Dengan "-file -" stdin sudah terpakai untuk data CSV, sehingga
+			// pertanyaan dibaca langsung dari terminal (/dev/tty), kecuali
+			// pertanyaannya sudah diberikan lewat -query atau -queries
+			if *query == "" && *queriesPath == "" {
+				tty, err := os.Open("/dev/tty")
+				if err != nil {
+					return ioErrorf("reading the CSV from stdin requires a terminal to read queries from (or use -query or -queries): %w", err)
+				}
+				defer tty.Close()
+				queryInput = tty
+			}
+		} else {
+			// Buka file CSV yang diminta
+			file, err := os.Open(path)
+			if err != nil {
+				// Jika terjadi error saat membuka file, kembalikan pesan yang
+				// menjelaskan cara memperbaikinya
+				return describeOpenError(path, err)
+			}
+			// Pastikan file ditutup setelah selesai digunakan
+			defer file.Close()
+			csvInput = file
+		}
+
+		// Ubah file Latin-1 ke UTF-8 sebelum dibaca, agar huruf beraksen
+		// tidak rusak
+		csvInput, err = tableqa.DecodeReader(csvInput, csvEncoding)
+		if err != nil {
+			return configErrorf("invalid -encoding: %w", err)
+		}
+
+		// Pilih satu tabel dari file yang berisi beberapa tabel
+		if isFlagSet("table-index") {
+			data, err := io.ReadAll(csvInput)
+			if err != nil {
+				return ioErrorf("failed to read CSV data from %s: %w", path, err)
+			}
+			sub, err := tableqa.TableAt(string(data), *tableIndex)
+			if err != nil {
+				return configErrorf("invalid -table-index for %s: %w", path, err)
+			}
+			csvInput = strings.NewReader(sub)
+		}
+
+		// Pemisah kolom mengikuti ekstensi file kecuali diatur lewat -delimiter
+		delim, err := delimiterFor(path, *delimiter)
+		if err != nil {
+			return configErrorf("invalid -delimiter: %w", err)
+		}
+
+		// Baca CSV langsung dari file (atau stdin) menjadi tabel berurutan
+		// tanpa menyalin seluruh isinya ke string lebih dulu
+		t, err := tableqa.CsvReaderToTableWithDelimiter(csvInput, delim)
+		if err != nil {
+			// Jika terjadi error saat membaca data CSV, hentikan program
+			return ioErrorf("failed to read CSV data from %s: %w", path, err)
+		}
+		if len(t.Columns) > 0 {
+			logger.Printf("Parsed %d rows from %s", len(t.Data[t.Columns[0]]), path)
+		}
+		tables = append(tables, t)
+		sources = append(sources, path)
 	}
 
-	// Panggil metode summarization dari klien inference dengan artikel yang telah di-JSON-kan
-	summary, err := ic.Summarization(context.Background(), &hf.SummarizationRequest{
-		Inputs: []string{string(articleJSON)},
+	// Unduh CSV dari -url dengan client yang sama (termasuk proxy)
+	if *csvURL != "" {
+		// Ekstensi diambil dari path URL, tanpa query string
+		var urlPath string
+		if u, err := url.Parse(*csvURL); err == nil {
+			urlPath = u.Path
+		}
+		delim, err := delimiterFor(urlPath, *delimiter)
+		if err != nil {
+			return configErrorf("invalid -delimiter: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		t, err := tableqa.FetchCSVWithDelimiter(ctx, httpClient, *csvURL, 0, delim)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to fetch CSV from %s: %w", *csvURL, err)
+		}
+		if len(t.Columns) > 0 {
+			logger.Printf("Parsed %d rows from %s", len(t.Data[t.Columns[0]]), *csvURL)
+		}
+		tables = append(tables, t)
+		sources = append(sources, *csvURL)
+	}
+
+	// Gabungkan semua file menjadi satu tabel; header setiap file harus sama
+	table := tables[0]
+	if len(tables) > 1 {
+		merged, err := tableqa.MergeTableList(tables...)
+		if err != nil {
+			return configErrorf("failed to merge CSV files: %w", err)
+		}
+		table = merged
+	}
+	// CSV kosong tidak perlu dikirim ke API; mode classify dan translate
+	// tidak memakai tabel. Diperiksa setelah digabung agar file di -files
+	// yang hanya berisi header tetap diterima selama file lain berisi baris.
+	if usesTable(*mode) {
+		if err := tableqa.CheckTableData(table); err != nil {
+			return ioErrorf("failed to read CSV data from %s: %w", strings.Join(sources, ", "), err)
+		}
+	}
+
+	// Ganti nama kolom lewat -rename lebih dulu, agar -filter, -columns dan
+	// model memakai nama yang sama
+	if *rename != "" {
+		renames, err := parseRenames(*rename)
+		if err != nil {
+			return configErrorf("invalid -rename: %w", err)
+		}
+		renames, err = resolveKeys(table.Columns, renames, !*caseSensitive)
+		if err != nil {
+			return configErrorf("invalid -rename: %w (available: %s)", err, strings.Join(table.Columns, ", "))
+		}
+		renamed, err := tableqa.RenameTableColumns(table, renames)
+		if err != nil {
+			return configErrorf("invalid -rename: %w (available: %s)", err, strings.Join(table.Columns, ", "))
+		}
+		table = renamed
+	}
+
+	// Saring baris lewat -filter sebelum kolom dipilih, agar kolom filter
+	// tidak harus ikut dikirim
+	if *filter != "" {
+		filters, err := parseFilters(*filter)
+		if err != nil {
+			return configErrorf("invalid -filter: %w", err)
+		}
+		filters, err = resolveKeys(table.Columns, filters, !*caseSensitive)
+		if err != nil {
+			return configErrorf("invalid -filter: %w (available: %s)", err, strings.Join(table.Columns, ", "))
+		}
+		table = tableqa.Table{Columns: table.Columns, Data: tableqa.ApplyFilters(table.Data, filters)}
+		if err := tableqa.CheckTableData(table); err != nil {
+			return configErrorf("no rows match -filter %q", *filter)
+		}
+	}
+
+	// Kirim hanya kolom yang diminta lewat -columns
+	if *columns != "" {
+		names, err := tableqa.ResolveColumns(table.Columns, splitList(*columns), !*caseSensitive)
+		if err != nil {
+			return configErrorf("invalid -columns: %w (available: %s)", err, strings.Join(table.Columns, ", "))
+		}
+		selected, err := tableqa.SelectTableColumns(table, names)
+		if err != nil {
+			return configErrorf("invalid -columns: %w (available: %s)", err, strings.Join(table.Columns, ", "))
+		}
+		table = selected
+	}
+
+	// Nomor baris ditambahkan setelah -columns agar selalu ikut dikirim
+	if *addRowNumbers {
+		numbered, err := tableqa.AddRowNumbers(table)
+		if err != nil {
+			return configErrorf("invalid -add-row-numbers: %w", err)
+		}
+		table = numbered
+	}
+
+	// Tampilkan tabel yang akan dikirim agar hasil parsing CSV bisa diperiksa
+	if *showTable {
+		if err := tableqa.FormatTable(os.Stderr, table, showTableRows); err != nil {
+			return fmt.Errorf("printing table: %w", err)
+		}
+	}
+
+	// -count hanya melaporkan ukuran tabel agar data bisa diperiksa tanpa memanggil API
+	if *count {
+		rows, cols := table.Dimensions()
+		fmt.Printf("%d columns, %d rows\n", cols, rows)
+		return nil
+	}
+
+	// -export hanya menulis ulang tabel yang sudah dibersihkan, tanpa bertanya ke model
+	if *export != "" {
+		if err := exportTable(*export, table); err != nil {
+			return ioErrorf("writing -export file: %w", err)
+		}
+		logger.Printf("Exported %d columns to %s", len(table.Columns), *export)
+		return nil
+	}
+
+	// Pada mode extractive-qa jawaban dicari di file -context, atau di tabel
+	// yang diratakan menjadi teks jika -context tidak diberikan
+	var qaContext string
+	if *mode == modeExtractiveQA {
+		qaContext = tableqa.FlattenTable(table)
+		if *contextPath != "" {
+			data, err := os.ReadFile(*contextPath)
+			if err != nil {
+				return ioErrorf("reading -context file: %w", err)
+			}
+			qaContext = string(data)
+		}
+	}
+
+	// Siapkan file hasil jika -out diberikan
+	var results *resultWriter
+	if *outPath != "" {
+		results, err = openResults(*outPath)
+		if err != nil {
+			return ioErrorf("failed to open results file: %w", err)
+		}
+		defer results.Close()
+	}
+
+	// Buat connector TAPAS dan klien inference baru menggunakan token yang diberikan.
+	// Pertanyaan yang diulang dalam satu sesi dijawab dari cache.
+	opts := []tableqa.Option{
+		tableqa.WithModel(model),
+		tableqa.WithRevision(*revision),
+		tableqa.WithPayloadFormat(tablePayload),
+		tableqa.WithColumnOrder(table.Columns),
+		tableqa.WithHTTPClient(httpClient),
+		tableqa.WithLogger(logger),
+		tableqa.WithRateLimit(*rateLimit),
+		tableqa.WithCache(tableqa.DefaultCacheSize),
+	}
+	if *dryRun {
+		// Pada dry run, permintaan yang akan dikirim dicetak ke stdout
+		opts = append(opts, tableqa.WithDryRun(), tableqa.WithLogger(log.New(os.Stdout, "", 0)))
+	}
+	if *offline != "" {
+		opts = append(opts, tableqa.WithReplay(*offline))
+	}
+	if *recordDir != "" {
+		opts = append(opts, tableqa.WithRecord(*recordDir))
+	}
+	connector := tableqa.NewAIModelConnector(opts...)
+	if *mode == modeQA {
+		logger.Printf("Sending queries to %s", connector.ModelURL())
+	}
+	hfClient := hf.NewInferenceClient(token, func(o *hf.InferenceClientOptions) {
+		o.HTTPClient = httpClient
 	})
-	if err != nil {
-		// Jika terjadi error saat melakukan summarization, log error dan hentikan program
-		log.Fatalf("Error summarizing text: %v", err)
+	a := &app{
+		mode:             *mode,
+		table:            table,
+		types:            tableqa.InferColumnTypes(table.Data),
+		instruction:      *instruction,
+		qaContext:        qaContext,
+		srcLang:          *srcLang,
+		tgtLang:          *tgtLang,
+		connector:        connector,
+		chunkSize:        *chunkSize,
+		precision:        *precision,
+		minScore:         *minScore,
+		normalizeNumbers: *normalizeNumbers,
+		hf:               hfClient,
+		token:            token,
+		format:           *format,
+		pretty:           *pretty,
+		maxCells:         *maxCells,
+		template:         tmpl,
+		results:          results,
+		logger:           logger,
+	}
+	// Progres ditulis ke stderr agar stdout tetap bersih untuk hasil yang di-pipe
+	if *progress {
+		a.progress = progressPrinter(os.Stderr)
+	}
+
+	// Ctrl-C membatalkan ctx sehingga permintaan yang sedang berjalan ikut
+	// dihentikan dan loop keluar dengan rapi
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// -queries menjawab semua pertanyaan di file lalu keluar
+	if *queriesPath != "" {
+		queries, err := readQueriesFile(*queriesPath)
+		if err != nil {
+			return ioErrorf("reading -queries file: %w", err)
+		}
+		logger.Printf("Read %d queries from %s", len(queries), *queriesPath)
+		return a.runQueries(ctx, queries, os.Stdout)
 	}
 
-	// Cetak teks ringkasan pertama yang dikembalikan oleh API
-	fmt.Println(summary[0].SummaryText)
+	// -query menjawab satu pertanyaan lalu keluar; tanpa -query pertanyaan
+	// dibaca secara interaktif
+	return a.start(ctx, *query, queryInput, os.Stdout)
 }
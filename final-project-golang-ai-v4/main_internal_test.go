@@ -0,0 +1,1019 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	hf "github.com/hupe1980/go-huggingface"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CLI", func() {
+	Describe("writeResponse", func() {
+		response := Response{
+			Answer:      "SUM > 1.2, 0.8",
+			Coordinates: [][]int{{0, 3}, {8, 3}},
+			Cells:       []string{"1.2", "0.8"},
+			Aggregator:  "SUM",
+		}
+
+		It("writes the human-readable answer in text format", func() {
+			var out bytes.Buffer
+			Expect(writeResponse(&out, response, formatText, false, 0)).Should(Succeed())
+			Expect(out.String()).Should(Equal(response.String() + "\n"))
+		})
+
+		It("writes the full response in json format", func() {
+			var out bytes.Buffer
+			Expect(writeResponse(&out, response, formatJSON, false, 0)).Should(Succeed())
+			Expect(out.String()).Should(MatchJSON(`{
+				"answer": "SUM > 1.2, 0.8",
+				"coordinates": [[0, 3], [8, 3]],
+				"cells": ["1.2", "0.8"],
+				"aggregator": "SUM"
+			}`))
+		})
+
+		It("writes compact json on one line by default", func() {
+			var out bytes.Buffer
+			Expect(writeResponse(&out, response, formatJSON, false, 0)).Should(Succeed())
+			Expect(out.String()).Should(Equal(`{"answer":"SUM \u003e 1.2, 0.8","coordinates":[[0,3],[8,3]],"cells":["1.2","0.8"],"aggregator":"SUM"}` + "\n"))
+		})
+
+		It("indents json with two spaces when pretty is set", func() {
+			var compact, pretty bytes.Buffer
+			Expect(writeResponse(&compact, response, formatJSON, false, 0)).Should(Succeed())
+			Expect(writeResponse(&pretty, response, formatJSON, true, 0)).Should(Succeed())
+
+			Expect(pretty.String()).Should(HavePrefix("{\n  \"answer\": \"SUM \\u003e 1.2, 0.8\",\n  \"coordinates\": [\n    [\n"))
+			Expect(pretty.String()).Should(MatchJSON(compact.String()))
+		})
+
+		It("ignores pretty in text format", func() {
+			var out bytes.Buffer
+			Expect(writeResponse(&out, response, formatText, true, 0)).Should(Succeed())
+			Expect(out.String()).Should(Equal(response.String() + "\n"))
+		})
+
+		It("limits the printed cells in text format but not in json", func() {
+			many := Response{Answer: "Refrigerator", Cells: []string{"A", "B", "C", "D", "E"}}
+
+			var text, js bytes.Buffer
+			Expect(writeResponse(&text, many, formatText, false, 2)).Should(Succeed())
+			Expect(writeResponse(&js, many, formatJSON, false, 2)).Should(Succeed())
+
+			Expect(text.String()).Should(Equal("Refrigerator\nCells: A, B, ...and 3 more\n"))
+			Expect(js.String()).Should(ContainSubstring(`"cells":["A","B","C","D","E"]`))
+		})
+
+		It("rejects an unknown format", func() {
+			var out bytes.Buffer
+			Expect(writeResponse(&out, response, "xml", false, 0)).ShouldNot(Succeed())
+			Expect(out.Len()).Should(BeZero())
+		})
+	})
+
+	Describe("writeTemplate", func() {
+		response := Response{Answer: "SUM > 1.2, 0.8", Cells: []string{"1.2", "0.8"}, Aggregator: "SUM"}
+
+		DescribeTable("formats the answer with a valid template",
+			func(text, expected string) {
+				tmpl, err := parseTemplate(text)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				var out bytes.Buffer
+				Expect(writeTemplate(&out, response, tmpl)).Should(Succeed())
+				Expect(out.String()).Should(Equal(expected))
+			},
+			Entry("fields", "The answer is {{.Answer}} ({{.Aggregator}})", "The answer is SUM > 1.2, 0.8 (SUM)\n"),
+			Entry("range over cells", "{{range .Cells}}{{.}};{{end}}", "1.2;0.8;\n"),
+			Entry("trailing newline kept once", "{{.Aggregator}}\n", "SUM\n"),
+		)
+
+		DescribeTable("rejects invalid syntax when parsing",
+			func(text string) {
+				_, err := parseTemplate(text)
+				Expect(err).Should(MatchError(ContainSubstring("template: output")))
+			},
+			Entry("unclosed action", "The answer is {{.Answer"),
+			Entry("unknown function", "{{upper .Answer}}"),
+			Entry("unterminated range", "{{range .Cells}}{{.}}"),
+		)
+
+		It("writes nothing when a field does not exist", func() {
+			tmpl, err := parseTemplate("{{.Answer}} {{.Confidence}}")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			var out bytes.Buffer
+			Expect(writeTemplate(&out, response, tmpl)).Should(MatchError(ContainSubstring("Confidence")))
+			Expect(out.Len()).Should(BeZero())
+		})
+	})
+
+	Describe("exportTable", func() {
+		It("round-trips a CSV with quoted fields unchanged", func() {
+			input := "Name,Note,Amount\n" +
+				"\"Smith, John\",\"said \"\"hi\"\"\",1.20\n" +
+				"Jane,\"line one\nline two\",007\n"
+			table, err := tableqa.CsvToTable(input)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			path := filepath.Join(GinkgoT().TempDir(), "out.csv")
+			Expect(exportTable(path, table)).Should(Succeed())
+
+			exported, err := os.ReadFile(path)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(string(exported)).Should(Equal(input))
+		})
+
+		It("reports a file that cannot be created", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "missing", "out.csv")
+			Expect(exportTable(path, tableqa.Table{Columns: []string{"Name"}})).ShouldNot(Succeed())
+		})
+	})
+
+	Describe("writeModels", func() {
+		models := []tableqa.ModelInfo{
+			{ID: "google/tapas-base-finetuned-wtq", Task: tableqa.TaskTableQA, Description: "TAPAS base"},
+			{ID: "facebook/bart-large-cnn", Task: tableqa.TaskSummarization, Description: "BART summaries"},
+		}
+
+		It("writes an aligned table in text format", func() {
+			var out bytes.Buffer
+			Expect(writeModels(&out, models, formatText)).Should(Succeed())
+			Expect(out.String()).Should(Equal(
+				"ID                               TASK                      DESCRIPTION\n" +
+					"google/tapas-base-finetuned-wtq  table-question-answering  TAPAS base\n" +
+					"facebook/bart-large-cnn          summarization             BART summaries\n"))
+		})
+
+		It("writes a JSON array in json format", func() {
+			var out bytes.Buffer
+			Expect(writeModels(&out, models, formatJSON)).Should(Succeed())
+			Expect(out.String()).Should(MatchJSON(`[
+				{"id": "google/tapas-base-finetuned-wtq", "task": "table-question-answering", "description": "TAPAS base"},
+				{"id": "facebook/bart-large-cnn", "task": "summarization", "description": "BART summaries"}
+			]`))
+		})
+	})
+
+	Describe("describeOpenError", func() {
+		It("explains a missing file as an IO error", func() {
+			_, err := os.Open(filepath.Join(GinkgoT().TempDir(), "data-series.csv"))
+			err = describeOpenError("data-series.csv", err)
+			Expect(err).Should(MatchError("CSV file not found: data-series.csv. Use -file to specify a different path."))
+			Expect(exitCode(err)).Should(Equal(exitIO))
+		})
+
+		It("recognises a wrapped not-exist error", func() {
+			err := &fs.PathError{Op: "open", Path: "data.csv", Err: fs.ErrNotExist}
+			err2 := describeOpenError("data.csv", fmt.Errorf("opening: %w", err))
+			Expect(err2).Should(MatchError(HavePrefix("CSV file not found: data.csv.")))
+		})
+
+		It("explains a permission error", func() {
+			err := &fs.PathError{Op: "open", Path: "data.csv", Err: fs.ErrPermission}
+			err2 := describeOpenError("data.csv", err)
+			Expect(err2).Should(MatchError(HavePrefix("Permission denied reading CSV file: data.csv.")))
+			Expect(exitCode(err2)).Should(Equal(exitIO))
+		})
+
+		It("falls back to the original error", func() {
+			original := errors.New("disk on fire")
+			err := describeOpenError("data.csv", original)
+			Expect(err).Should(MatchError(ContainSubstring("disk on fire")))
+			Expect(errors.Is(err, original)).Should(BeTrue())
+			Expect(exitCode(err)).Should(Equal(exitIO))
+		})
+	})
+
+	Describe("exitCode", func() {
+		DescribeTable("maps each failure category to its exit code",
+			func(err error, expected int) {
+				Expect(exitCode(err)).Should(Equal(expected))
+			},
+			Entry("success", nil, 0),
+			Entry("a config error", configErrorf("unknown mode %q", "chat"), exitConfig),
+			Entry("an IO error", ioErrorf("failed to read CSV data: %w", errors.New("bad quote")), exitIO),
+			Entry("a file error", fmt.Errorf("writing results file: %w", &fs.PathError{Op: "write", Path: "out.csv", Err: fs.ErrClosed}), exitIO),
+			Entry("a network error", &url.Error{Op: "Post", URL: tableqa.DefaultBaseURL, Err: &net.DNSError{Err: "no such host", Name: "api-inference.huggingface.co"}}, exitNetwork),
+			Entry("a timeout", fmt.Errorf("query: %w", context.DeadlineExceeded), exitNetwork),
+			Entry("a server error", &tableqa.APIError{StatusCode: http.StatusBadGateway}, exitNetwork),
+			Entry("a rejected token", fmt.Errorf("token check failed: %w", tableqa.ErrInvalidToken), exitAuth),
+			Entry("an unauthorized API call", &tableqa.APIError{StatusCode: http.StatusUnauthorized}, exitAuth),
+			Entry("a forbidden API call", &tableqa.APIError{StatusCode: http.StatusForbidden}, exitAuth),
+			Entry("a bad request", &tableqa.APIError{StatusCode: http.StatusBadRequest}, exitFailure),
+			Entry("failed queries", errQueriesFailed, exitFailure),
+			Entry("failed queries ending in a server error", &queriesFailedError{count: 2, last: &tableqa.APIError{StatusCode: http.StatusServiceUnavailable}}, exitNetwork),
+			Entry("failed queries ending in a bad request", &queriesFailedError{count: 1, last: &tableqa.APIError{StatusCode: http.StatusBadRequest}}, exitFailure),
+			Entry("Ctrl-C", context.Canceled, exitInterrupted),
+			Entry("anything else", errors.New("boom"), exitFailure),
+		)
+	})
+
+	Describe("resolveModel", func() {
+		DescribeTable("prefers the flag, then the environment, then the default",
+			func(flagValue, envValue, expected string) {
+				Expect(resolveModel(flagValue, envValue)).Should(Equal(expected))
+			},
+			Entry("flag and env set", "google/tapas-large-finetuned-wtq", "google/tapas-mini-finetuned-wtq", "google/tapas-large-finetuned-wtq"),
+			Entry("only env set", "", "google/tapas-mini-finetuned-wtq", "google/tapas-mini-finetuned-wtq"),
+			Entry("neither set", "", "", tableqa.DefaultModel),
+			Entry("blank values", "  ", " ", tableqa.DefaultModel),
+		)
+	})
+
+	Describe("splitList", func() {
+		DescribeTable("splits a comma-separated list",
+			func(input string, expected []string) {
+				Expect(splitList(input)).Should(Equal(expected))
+			},
+			Entry("simple", "Name,Age", []string{"Name", "Age"}),
+			Entry("spaces around items", " Name , Energy_Consumption ", []string{"Name", "Energy_Consumption"}),
+			Entry("empty items", "Name,,Age,", []string{"Name", "Age"}),
+			Entry("empty string", "", nil),
+		)
+	})
+
+	Describe("parseFilters", func() {
+		DescribeTable("parses column=value pairs",
+			func(input string, expected map[string]string) {
+				filters, err := parseFilters(input)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(filters).Should(Equal(expected))
+			},
+			Entry("single filter", "Region=EU", map[string]string{"Region": "EU"}),
+			Entry("multiple filters with spaces", " Region = EU , Room=Living Room", map[string]string{"Region": "EU", "Room": "Living Room"}),
+			Entry("empty value", "Region=", map[string]string{"Region": ""}),
+		)
+
+		DescribeTable("rejects malformed filters",
+			func(input string) {
+				_, err := parseFilters(input)
+				Expect(err).Should(MatchError(ContainSubstring("column=value")))
+			},
+			Entry("missing equals sign", "Region"),
+			Entry("missing column", "=EU"),
+		)
+	})
+
+	Describe("parseRenames", func() {
+		It("parses old=new pairs", func() {
+			renames, err := parseRenames(" col_1 = Name ,Total_Sales_USD=Sales")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(renames).Should(Equal(map[string]string{"col_1": "Name", "Total_Sales_USD": "Sales"}))
+		})
+
+		DescribeTable("rejects malformed renames",
+			func(input string) {
+				_, err := parseRenames(input)
+				Expect(err).Should(MatchError(ContainSubstring("old=new")))
+			},
+			Entry("missing equals sign", "col_1"),
+			Entry("missing old name", "=Name"),
+			Entry("missing new name", "col_1="),
+		)
+	})
+
+	Describe("resolveKeys", func() {
+		columns := []string{"Region", "Room"}
+
+		It("replaces each key with the header casing", func() {
+			resolved, err := resolveKeys(columns, map[string]string{"region": "EU", "ROOM": "Kitchen"}, true)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resolved).Should(Equal(map[string]string{"Region": "EU", "Room": "Kitchen"}))
+		})
+
+		It("rejects a different casing when case-sensitive", func() {
+			_, err := resolveKeys(columns, map[string]string{"region": "EU"}, false)
+			Expect(err).Should(MatchError(tableqa.ErrUnknownColumn))
+		})
+
+		It("rejects two keys for the same column", func() {
+			_, err := resolveKeys(columns, map[string]string{"region": "EU", "REGION": "US"}, true)
+			Expect(err).Should(MatchError(`"REGION" and "region" both refer to column "Region"`))
+		})
+	})
+
+	Describe("loadEnv", func() {
+		It("ignores a missing default env file", func() {
+			Expect(loadEnv(filepath.Join(GinkgoT().TempDir(), ".env"), false)).Should(Succeed())
+		})
+
+		It("fails on a missing env file that was asked for explicitly", func() {
+			Expect(loadEnv(filepath.Join(GinkgoT().TempDir(), "custom.env"), true)).ShouldNot(Succeed())
+		})
+
+		It("loads variables without overriding the real environment", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "custom.env")
+			Expect(os.WriteFile(path, []byte("TABLEQA_TEST_FROM_FILE=file\nTABLEQA_TEST_FROM_ENV=file\n"), 0o600)).Should(Succeed())
+			os.Setenv("TABLEQA_TEST_FROM_ENV", "env")
+			defer os.Unsetenv("TABLEQA_TEST_FROM_ENV")
+			defer os.Unsetenv("TABLEQA_TEST_FROM_FILE")
+
+			Expect(loadEnv(path, true)).Should(Succeed())
+			Expect(os.Getenv("TABLEQA_TEST_FROM_FILE")).Should(Equal("file"))
+			Expect(os.Getenv("TABLEQA_TEST_FROM_ENV")).Should(Equal("env"))
+		})
+	})
+
+	Describe("readTokenFile", func() {
+		writeToken := func(content string) string {
+			path := filepath.Join(GinkgoT().TempDir(), "token")
+			Expect(os.WriteFile(path, []byte(content), 0o600)).Should(Succeed())
+			return path
+		}
+
+		It("trims surrounding whitespace and the trailing newline", func() {
+			token, err := readTokenFile(writeToken("  hf_secret\r\n\n"))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(token).Should(Equal("hf_secret"))
+		})
+
+		It("rejects an empty file", func() {
+			path := writeToken(" \n")
+			_, err := readTokenFile(path)
+			Expect(err).Should(MatchError("token file " + path + " is empty"))
+		})
+
+		It("reports an unreadable file", func() {
+			_, err := readTokenFile(filepath.Join(GinkgoT().TempDir(), "missing"))
+			Expect(errors.Is(err, fs.ErrNotExist)).Should(BeTrue())
+		})
+	})
+
+	Describe("loadConfig", func() {
+		writeConfig := func(name, content string) string {
+			path := filepath.Join(GinkgoT().TempDir(), name)
+			Expect(os.WriteFile(path, []byte(content), 0o600)).Should(Succeed())
+			return path
+		}
+
+		precision := 4
+		want := Config{Model: "google/tapas-large-finetuned-wtq", Timeout: "2m", RateLimit: 30, Precision: &precision}
+
+		DescribeTable("reads the format matching the extension",
+			func(name, content string) {
+				cfg, err := LoadConfig(writeConfig(name, content))
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(cfg).Should(Equal(want))
+			},
+			Entry("yaml", "config.yaml", "model: google/tapas-large-finetuned-wtq\ntimeout: 2m\nrate-limit: 30\nprecision: 4\n"),
+			Entry("yml", "config.yml", "model: google/tapas-large-finetuned-wtq\ntimeout: 2m\nrate-limit: 30\nprecision: 4\n"),
+			Entry("json", "config.json", `{"model": "google/tapas-large-finetuned-wtq", "timeout": "2m", "rate-limit": 30, "precision": 4}`),
+		)
+
+		DescribeTable("rejects an invalid file",
+			func(name, content, message string) {
+				_, err := LoadConfig(writeConfig(name, content))
+				Expect(err).Should(MatchError(ContainSubstring(message)))
+			},
+			Entry("unknown yaml field", "config.yaml", "modle: x\n", "modle"),
+			Entry("unknown json field", "config.json", `{"modle": "x"}`, "modle"),
+			Entry("malformed json", "config.json", `{"model": `, "parsing"),
+			Entry("unsupported extension", "config.toml", `model = "x"`, `unsupported config file extension ".toml"`),
+		)
+
+		It("fails on an unreadable file", func() {
+			_, err := LoadConfig(filepath.Join(GinkgoT().TempDir(), "missing.yaml"))
+			Expect(errors.Is(err, fs.ErrNotExist)).Should(BeTrue())
+		})
+
+		It("fills only the flags not given on the command line", func() {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			model := flags.String("model", "", "")
+			timeout := flags.String("timeout", "30s", "")
+			rateLimit := flags.Int("rate-limit", 0, "")
+			precisionFlag := flags.Int("precision", 2, "")
+			format := flags.String("format", formatText, "")
+			Expect(flags.Parse([]string{"-timeout", "5m"})).Should(Succeed())
+
+			Expect(want.applyTo(flags)).Should(Succeed())
+			Expect(*model).Should(Equal("google/tapas-large-finetuned-wtq"))
+			Expect(*timeout).Should(Equal("5m"))
+			Expect(*rateLimit).Should(Equal(30))
+			Expect(*precisionFlag).Should(Equal(4))
+			Expect(*format).Should(Equal(formatText))
+		})
+
+		It("does not count config values as flags given on the command line", func() {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			format := flags.String("format", formatText, "")
+			flags.String("template", "", "")
+			Expect(flags.Parse([]string{"-template", "{{.Answer}}"})).Should(Succeed())
+
+			saved := cliFlags
+			defer func() { cliFlags = saved }()
+			cliFlags = visitedFlags(flags)
+
+			Expect(Config{Format: formatText}.applyTo(flags)).Should(Succeed())
+			Expect(*format).Should(Equal(formatText))
+			Expect(isFlagSet("template")).Should(BeTrue())
+			// -template bersama format dari config tidak boleh dianggap bentrok
+			Expect(isFlagSet("format")).Should(BeFalse())
+		})
+	})
+
+	Describe("app.answer", func() {
+		It("asks the TAPAS model in qa mode", func() {
+			var sent Inputs
+			connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(doerFunc(func(req *http.Request) (*http.Response, error) {
+				Expect(req.URL.String()).Should(HaveSuffix("/models/" + tableqa.DefaultModel))
+				Expect(json.NewDecoder(req.Body).Decode(&sent)).Should(Succeed())
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader(`{"answer": "SUM > 1.2, 0.8", "cells": ["1.2", "0.8"], "aggregator": "SUM"}`)),
+				}, nil
+			})))
+
+			table, err := tableqa.CsvToTable("Appliance,Energy_Consumption\nRefrigerator,1.2\nTV,0.8")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			a := &app{
+				mode:      modeQA,
+				table:     table,
+				connector: connector,
+				token:     "token",
+				logger:    log.New(ioutil.Discard, "", 0),
+			}
+			answer, err := a.answer(context.Background(), "What is the total energy consumption?")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(answer.String()).Should(Equal("SUM > 1.2, 0.8\nAggregator: SUM\nCells: 1.2, 0.8"))
+			Expect(sent.Table).Should(Equal(table.Data))
+			Expect(sent.Query).Should(Equal("What is the total energy consumption?"))
+		})
+
+		It("sends normalized numbers but keeps the original table", func() {
+			var sent Inputs
+			connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(doerFunc(func(req *http.Request) (*http.Response, error) {
+				Expect(json.NewDecoder(req.Body).Decode(&sent)).Should(Succeed())
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader(`{"answer": "2050.00", "aggregator": "SUM"}`)),
+				}, nil
+			})))
+
+			table, err := tableqa.CsvToTable("Appliance,Cost\nRefrigerator,\"$1,200.00\"\nTV,$850.00")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			a := &app{
+				mode:             modeQA,
+				table:            table,
+				connector:        connector,
+				normalizeNumbers: true,
+				token:            "token",
+				logger:           log.New(ioutil.Discard, "", 0),
+			}
+			_, err = a.answer(context.Background(), "What is the total cost?")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(sent.Table["Cost"]).Should(Equal([]string{"1200.00", "850.00"}))
+			Expect(a.table.Data["Cost"]).Should(Equal([]string{"$1,200.00", "$850.00"}))
+		})
+
+		It("rounds numeric aggregate answers to the configured precision", func() {
+			connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(doerFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader(`{"answer": "2.0000000001", "cells": ["1.2", "0.8"], "aggregator": "SUM"}`)),
+				}, nil
+			})))
+
+			table, err := tableqa.CsvToTable("Appliance,Energy_Consumption\nRefrigerator,1.2\nTV,0.8")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			a := &app{
+				mode:      modeQA,
+				table:     table,
+				connector: connector,
+				precision: tableqa.DefaultPrecision,
+				token:     "token",
+				logger:    log.New(ioutil.Discard, "", 0),
+			}
+			answer, err := a.answer(context.Background(), "What is the total energy consumption?")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(answer.String()).Should(Equal("2\nAggregator: SUM\nCells: 1.2, 0.8"))
+		})
+
+		DescribeTable("flags answers below -min-score",
+			func(body, expected string) {
+				connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(doerFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+				})))
+
+				table, err := tableqa.CsvToTable("Appliance,Room\nRefrigerator,Kitchen\nTV,Living Room")
+				Expect(err).ShouldNot(HaveOccurred())
+
+				a := &app{
+					mode:      modeQA,
+					table:     table,
+					connector: connector,
+					minScore:  0.5,
+					token:     "token",
+					logger:    log.New(ioutil.Discard, "", 0),
+				}
+				answer, err := a.answer(context.Background(), "Which appliance is in the kitchen?")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(answer.String()).Should(Equal(expected))
+			},
+			Entry("a score below the threshold", `{"answer": "Refrigerator", "score": 0.31}`, "low confidence (score 0.31): Refrigerator"),
+			Entry("a score above the threshold", `{"answer": "Refrigerator", "score": 0.93}`, "Refrigerator"),
+			Entry("no score", `{"answer": "Refrigerator"}`, "Refrigerator"),
+		)
+
+		It("searches the context in extractive-qa mode", func() {
+			var sent hf.QuestionAnsweringRequest
+			client := hf.NewInferenceClient("token", func(o *hf.InferenceClientOptions) {
+				o.HTTPClient = doerFunc(func(req *http.Request) (*http.Response, error) {
+					Expect(req.URL.String()).Should(HaveSuffix("/models/" + tableqa.DefaultQAModel))
+					Expect(json.NewDecoder(req.Body).Decode(&sent)).Should(Succeed())
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       ioutil.NopCloser(strings.NewReader(`{"answer": "Kitchen", "score": 0.8, "start": 32, "end": 39}`)),
+					}, nil
+				})
+			})
+
+			a := &app{
+				mode:      modeExtractiveQA,
+				qaContext: "The refrigerator is kept in the Kitchen.",
+				hf:        client,
+				logger:    log.New(ioutil.Discard, "", 0),
+			}
+			answer, err := a.answer(context.Background(), "Where is the refrigerator?")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(answer.String()).Should(Equal("Kitchen (score 0.8000)"))
+			Expect(sent.Inputs).Should(Equal(hf.QuestionAnsweringInputs{
+				Question: "Where is the refrigerator?",
+				Context:  "The refrigerator is kept in the Kitchen.",
+			}))
+		})
+
+		It("translates the query text in translate mode", func() {
+			var sent hf.TranslationRequest
+			client := hf.NewInferenceClient("token", func(o *hf.InferenceClientOptions) {
+				o.HTTPClient = doerFunc(func(req *http.Request) (*http.Response, error) {
+					Expect(req.URL.String()).Should(HaveSuffix("/models/Helsinki-NLP/opus-mt-en-id"))
+					Expect(json.NewDecoder(req.Body).Decode(&sent)).Should(Succeed())
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       ioutil.NopCloser(strings.NewReader(`[{"translation_text": "Berapa total konsumsi energi?"}]`)),
+					}, nil
+				})
+			})
+
+			a := &app{
+				mode:    modeTranslate,
+				srcLang: "en",
+				tgtLang: "id",
+				hf:      client,
+				logger:  log.New(ioutil.Discard, "", 0),
+			}
+			answer, err := a.answer(context.Background(), "What is the total energy consumption?")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(answer.String()).Should(Equal("Berapa total konsumsi energi?"))
+			Expect(sent.Inputs).Should(Equal([]string{"What is the total energy consumption?"}))
+		})
+
+		It("rejects an unknown mode", func() {
+			a := &app{mode: "chat", logger: log.New(ioutil.Discard, "", 0)}
+			_, err := a.answer(context.Background(), "hello")
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("summaryInput", func() {
+		table, _ := tableqa.CsvToTable("Appliance,Energy_Consumption\nRefrigerator,1.2")
+
+		DescribeTable("builds the text sent for summarization",
+			func(instruction, query, want string) {
+				Expect(summaryInput(instruction, query, table)).Should(Equal(want))
+			},
+			Entry("with an instruction", "Summarize in one sentence:", "Energy report",
+				"Summarize in one sentence:\nEnergy report\n"+tableqa.FlattenTable(table)),
+			Entry("trims the instruction", "  Summarize in one sentence:\n", "Energy report",
+				"Summarize in one sentence:\nEnergy report\n"+tableqa.FlattenTable(table)),
+			Entry("without an instruction", "", "Energy report",
+				"Energy report\n"+tableqa.FlattenTable(table)),
+		)
+	})
+
+	Describe("resultWriter", func() {
+		It("writes a header once and appends a row per answer", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "results.csv")
+
+			w, err := openResults(path)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(w.Write("What is the total energy consumption?", Response{Answer: "SUM > 1.2, 0.8", Aggregator: "SUM"})).Should(Succeed())
+			Expect(w.Write("Which appliance is listed first?", Response{Answer: "Refrigerator", Aggregator: "NONE"})).Should(Succeed())
+			Expect(w.Close()).Should(Succeed())
+
+			// Membuka ulang file yang sudah ada tidak menulis header lagi
+			w, err = openResults(path)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(w.Write("Is this positive?", tableqa.Classifications{{Label: "POSITIVE", Score: 0.9}})).Should(Succeed())
+			Expect(w.Close()).Should(Succeed())
+
+			data, err := os.ReadFile(path)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(string(data)).Should(Equal(
+				"query,answer,aggregator\n" +
+					"What is the total energy consumption?,\"SUM > 1.2, 0.8\",SUM\n" +
+					"Which appliance is listed first?,Refrigerator,NONE\n" +
+					"Is this positive?,POSITIVE: 0.9000,\n"))
+		})
+
+		It("fails when the file cannot be created", func() {
+			_, err := openResults(filepath.Join(GinkgoT().TempDir(), "missing", "results.csv"))
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("textColumnsInNumericQuery", func() {
+		columns := []string{"Appliance", "Energy_Consumption", "Room"}
+		types := map[string]string{
+			"Appliance":          tableqa.TypeString,
+			"Energy_Consumption": tableqa.TypeFloat,
+			"Room":               tableqa.TypeString,
+		}
+
+		DescribeTable("finds text columns named in numeric questions",
+			func(query string, expected []string) {
+				Expect(textColumnsInNumericQuery(query, columns, types)).Should(Equal(expected))
+			},
+			Entry("a text column", "What is the total appliance?", []string{"Appliance"}),
+			Entry("several text columns in column order", "Average room per appliance", []string{"Appliance", "Room"}),
+			Entry("a numeric column", "What is the total energy consumption?", nil),
+			Entry("a numeric column by its raw name", "sum of Energy_Consumption", nil),
+			Entry("a question that is not numeric", "Which appliance is in the kitchen?", nil),
+			Entry("a phrase", "How many appliance types are there?", []string{"Appliance"}),
+			Entry("min inside another word", "Which appliance runs for a minute?", nil),
+			Entry("sum inside another word", "Give a summary of each appliance", nil),
+		)
+	})
+
+	Describe("app.repl", func() {
+		newApp := func(client tableqa.HTTPDoer) *app {
+			table, err := tableqa.CsvToTable("Appliance,Energy_Consumption\nRefrigerator,1.2\nTV,0.8")
+			Expect(err).ShouldNot(HaveOccurred())
+			return &app{
+				mode:      modeQA,
+				table:     table,
+				connector: tableqa.NewAIModelConnector(tableqa.WithHTTPClient(client)),
+				token:     "token",
+				format:    formatText,
+				logger:    log.New(ioutil.Discard, "", 0),
+			}
+		}
+
+		okClient := doerFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"answer": "Refrigerator"}`)),
+			}, nil
+		})
+
+		It("answers each query until exit", func() {
+			var out bytes.Buffer
+			err := newApp(okClient).repl(context.Background(), strings.NewReader("Which appliance?\n\nexit\nignored\n"), &out)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(out.String()).Should(Equal(prompt + "Refrigerator\n" + prompt + prompt))
+		})
+
+		It("stops at the end of the input", func() {
+			var out bytes.Buffer
+			err := newApp(okClient).repl(context.Background(), strings.NewReader("Which appliance?"), &out)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(out.String()).Should(Equal(prompt + "Refrigerator\n" + prompt + "\n"))
+		})
+
+		It("reports failed queries after answering the rest", func() {
+			calls := 0
+			client := doerFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				if calls == 1 {
+					return &http.Response{StatusCode: http.StatusInternalServerError, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+				}
+				return okClient(req)
+			})
+
+			var out bytes.Buffer
+			err := newApp(client).repl(context.Background(), strings.NewReader("first\nsecond\n"), &out)
+			Expect(err).Should(MatchError(errQueriesFailed))
+			Expect(out.String()).Should(ContainSubstring("Refrigerator"))
+		})
+
+		It("reports progress after each answered or failed query", func() {
+			calls := 0
+			client := doerFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				if calls == 2 {
+					return &http.Response{StatusCode: http.StatusBadRequest, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+				}
+				return okClient(req)
+			})
+
+			var progress bytes.Buffer
+			a := newApp(client)
+			a.progress = progressPrinter(&progress)
+			err := a.repl(context.Background(), strings.NewReader("first\n\nsecond\nthird\n"), ioutil.Discard)
+			Expect(err).Should(MatchError(errQueriesFailed))
+			Expect(progress.String()).Should(Equal("processed 1\nprocessed 2\nprocessed 3\n"))
+		})
+
+		It("aborts an in-flight request when the context is cancelled", func() {
+			started := make(chan struct{})
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				<-release
+			}))
+			defer server.Close()
+			defer close(release)
+
+			a := newApp(server.Client())
+			a.connector.BaseURL = server.URL
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				<-started
+				cancel()
+			}()
+
+			var out bytes.Buffer
+			start := time.Now()
+			err := a.repl(ctx, strings.NewReader("Which appliance?\nsecond\n"), &out)
+			Expect(errors.Is(err, context.Canceled)).Should(BeTrue())
+			Expect(time.Since(start)).Should(BeNumerically("<", time.Second))
+			Expect(out.String()).Should(Equal(prompt + "\n"))
+		})
+
+		It("stops waiting for input when the context is cancelled", func() {
+			in, w := io.Pipe()
+			defer w.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			var out bytes.Buffer
+			err := newApp(okClient).repl(ctx, in, &out)
+			Expect(errors.Is(err, context.DeadlineExceeded)).Should(BeTrue())
+		})
+		Describe("app.start", func() {
+			It("answers the query from -query without reading input", func() {
+				var out bytes.Buffer
+				err := newApp(okClient).start(context.Background(), "  Which appliance?  ", strings.NewReader("ignored\n"), &out)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(out.String()).Should(Equal("Refrigerator\n"))
+			})
+
+			It("prompts for queries when -query is empty", func() {
+				var out bytes.Buffer
+				err := newApp(okClient).start(context.Background(), " ", strings.NewReader("Which appliance?\n"), &out)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(out.String()).Should(Equal(prompt + "Refrigerator\n" + prompt + "\n"))
+			})
+
+			It("returns the error of a failed -query", func() {
+				client := doerFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+				})
+				a := newApp(client)
+				a.connector.MaxRetries = 0
+
+				var out bytes.Buffer
+				err := a.start(context.Background(), "Which appliance?", strings.NewReader(""), &out)
+				Expect(exitCode(err)).Should(Equal(exitNetwork))
+				Expect(out.String()).Should(BeEmpty())
+			})
+		})
+	})
+
+	Describe("readQueries", func() {
+		It("skips blank lines and comments and keeps the order", func() {
+			queries, err := readQueries(strings.NewReader("# energy questions\nWhich appliance uses the most energy?\n\n  \n  # indented comment\nWhat is the total energy consumption?\n  How many TVs are there?  \n"))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(queries).Should(Equal([]string{
+				"Which appliance uses the most energy?",
+				"What is the total energy consumption?",
+				"How many TVs are there?",
+			}))
+		})
+
+		It("returns no queries for a file with only comments", func() {
+			queries, err := readQueries(strings.NewReader("# nothing yet\n\n"))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(queries).Should(BeEmpty())
+		})
+
+		It("reports a missing file", func() {
+			_, err := readQueriesFile(filepath.Join(GinkgoT().TempDir(), "questions.txt"))
+			Expect(errors.Is(err, fs.ErrNotExist)).Should(BeTrue())
+		})
+	})
+
+	Describe("app.runQueries", func() {
+		// echoClient menjawab setiap pertanyaan dengan teks pertanyaannya
+		// sendiri, atau gagal untuk pertanyaan "fail"
+		echoClient := doerFunc(func(req *http.Request) (*http.Response, error) {
+			var sent Inputs
+			if err := json.NewDecoder(req.Body).Decode(&sent); err != nil {
+				return nil, err
+			}
+			if sent.Query == "fail" {
+				return &http.Response{StatusCode: http.StatusBadRequest, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+			}
+			body, _ := json.Marshal(tableqa.Response{Answer: "answer to " + sent.Query})
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(body))}, nil
+		})
+
+		newApp := func() *app {
+			table, err := tableqa.CsvToTable("Appliance,Energy_Consumption\nRefrigerator,1.2\nTV,0.8")
+			Expect(err).ShouldNot(HaveOccurred())
+			return &app{
+				mode:      modeQA,
+				table:     table,
+				connector: tableqa.NewAIModelConnector(tableqa.WithHTTPClient(echoClient)),
+				token:     "token",
+				format:    formatText,
+				logger:    log.New(ioutil.Discard, "", 0),
+			}
+		}
+
+		It("prints each query and its answer in order", func() {
+			var out, progress bytes.Buffer
+			a := newApp()
+			a.progress = progressPrinter(&progress)
+			Expect(a.runQueries(context.Background(), []string{"first", "second", "third"}, &out)).Should(Succeed())
+			Expect(out.String()).Should(Equal("Q: first\nanswer to first\nQ: second\nanswer to second\nQ: third\nanswer to third\n"))
+			Expect(progress.String()).Should(HaveSuffix("processed 3/3\n"))
+		})
+
+		It("reports failed queries after answering the rest", func() {
+			var out bytes.Buffer
+			err := newApp().runQueries(context.Background(), []string{"first", "fail", "third"}, &out)
+			Expect(err).Should(MatchError(errQueriesFailed))
+			Expect(out.String()).Should(Equal("Q: first\nanswer to first\nQ: fail\nQ: third\nanswer to third\n"))
+		})
+
+		It("writes the answers that arrived before Ctrl-C", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			a := newApp()
+			// Ctrl-C ditekan saat pertanyaan kedua sedang dikirim
+			a.connector = tableqa.NewAIModelConnector(tableqa.WithConcurrency(1), tableqa.WithHTTPClient(doerFunc(func(req *http.Request) (*http.Response, error) {
+				var sent Inputs
+				if err := json.NewDecoder(req.Body).Decode(&sent); err != nil {
+					return nil, err
+				}
+				if sent.Query == "second" {
+					cancel()
+					return nil, ctx.Err()
+				}
+				body, _ := json.Marshal(tableqa.Response{Answer: "answer to " + sent.Query})
+				return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(body))}, nil
+			})))
+
+			var out bytes.Buffer
+			err := a.runQueries(ctx, []string{"first", "second", "third"}, &out)
+			Expect(errors.Is(err, context.Canceled)).Should(BeTrue())
+			Expect(out.String()).Should(Equal("Q: first\nanswer to first\n"))
+		})
+
+		It("skips the query cancelled by Ctrl-C in extractive-qa mode", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			// Ctrl-C ditekan saat pertanyaan kedua sedang dikirim
+			client := hf.NewInferenceClient("token", func(o *hf.InferenceClientOptions) {
+				o.HTTPClient = doerFunc(func(req *http.Request) (*http.Response, error) {
+					var sent hf.QuestionAnsweringRequest
+					if err := json.NewDecoder(req.Body).Decode(&sent); err != nil {
+						return nil, err
+					}
+					if sent.Inputs.Question == "second" {
+						cancel()
+						return nil, ctx.Err()
+					}
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       ioutil.NopCloser(strings.NewReader(`{"answer": "Kitchen", "score": 0.8, "start": 32, "end": 39}`)),
+					}, nil
+				})
+			})
+			a := &app{
+				mode:      modeExtractiveQA,
+				qaContext: "The refrigerator is kept in the Kitchen.",
+				hf:        client,
+				format:    formatText,
+				logger:    log.New(ioutil.Discard, "", 0),
+			}
+
+			var out bytes.Buffer
+			err := a.runQueries(ctx, []string{"first", "second", "third"}, &out)
+			Expect(errors.Is(err, context.Canceled)).Should(BeTrue())
+			Expect(out.String()).Should(Equal("Q: first\nKitchen (score 0.8000)\n"))
+		})
+
+		It("writes only the answers in json format", func() {
+			var out bytes.Buffer
+			a := newApp()
+			a.format = formatJSON
+			Expect(a.runQueries(context.Background(), []string{"first"}, &out)).Should(Succeed())
+			Expect(out.String()).Should(MatchJSON(`{"answer": "answer to first", "coordinates": null, "cells": null, "aggregator": ""}`))
+		})
+	})
+
+	Describe("progressPrinter", func() {
+		It("prints the count with the total when it is known", func() {
+			var out bytes.Buffer
+			progress := progressPrinter(&out)
+			progress(12, 50)
+			progress(3, 0)
+			Expect(out.String()).Should(Equal("processed 12/50\nprocessed 3\n"))
+		})
+	})
+
+	Describe("parseTimeout", func() {
+		DescribeTable("parses positive durations",
+			func(value string, want time.Duration) {
+				d, err := parseTimeout(value)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(d).Should(Equal(want))
+			},
+			Entry("seconds", "60s", time.Minute),
+			Entry("minutes", "2m", 2*time.Minute),
+			Entry("the default", tableqa.DefaultTimeout.String(), tableqa.DefaultTimeout),
+		)
+
+		DescribeTable("rejects other values",
+			func(value, message string) {
+				_, err := parseTimeout(value)
+				Expect(err).Should(MatchError(ContainSubstring(message)))
+			},
+			Entry("zero", "0s", "timeout must be positive, got 0s"),
+			Entry("negative", "-5s", "timeout must be positive, got -5s"),
+			Entry("missing unit", "60", "missing unit"),
+			Entry("not a duration", "soon", "invalid duration"),
+		)
+	})
+
+	Describe("delimiterFor", func() {
+		DescribeTable("picks the delimiter from the extension unless overridden",
+			func(name, override string, want rune) {
+				delim, err := delimiterFor(name, override)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(delim).Should(Equal(want))
+			},
+			Entry("csv file", "data.csv", "", ','),
+			Entry("tsv file", "data.tsv", "", '\t'),
+			Entry("upper-case extension", "DATA.TSV", "", '\t'),
+			Entry("no extension", "-", "", ','),
+			Entry("override on a csv file", "data.csv", ";", ';'),
+			Entry("override on a tsv file", "data.tsv", ",", ','),
+			Entry("tab by name", "data.csv", "tab", '\t'),
+			Entry("escaped tab", "data.csv", `\t`, '\t'),
+		)
+
+		It("rejects an override longer than one character", func() {
+			_, err := delimiterFor("data.csv", ";;")
+			Expect(err).Should(MatchError(ContainSubstring(`got ";;"`)))
+		})
+	})
+
+	Describe("validMode", func() {
+		It("accepts every documented mode", func() {
+			Expect(validMode(modeQA)).Should(BeTrue())
+			Expect(validMode(modeSummarize)).Should(BeTrue())
+			Expect(validMode(modeClassify)).Should(BeTrue())
+			Expect(validMode(modeExtractiveQA)).Should(BeTrue())
+			Expect(validMode(modeTranslate)).Should(BeTrue())
+			Expect(validMode("chat")).Should(BeFalse())
+		})
+	})
+})
+
+// doerFunc adalah tableqa.HTTPDoer tiruan yang memanggil fungsi biasa.
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -2,6 +2,7 @@ package main_test
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -101,7 +102,7 @@ value3,value4`
 			err := godotenv.Load()
 			Expect(err).ShouldNot(HaveOccurred())
 
-			result, err := connector.ConnectAIModel(payload, os.Getenv("HUGGINGFACE_TOKEN"))
+			result, err := connector.ConnectAIModel(context.Background(), payload, os.Getenv("HUGGINGFACE_TOKEN"))
 			Expect(err).ShouldNot(HaveOccurred())
 			Expect(result).Should(Equal(expected))
 		})
@@ -149,7 +150,7 @@ value3,value4`
 			err := godotenv.Load()
 			Expect(err).ShouldNot(HaveOccurred())
 
-			result, err := connector.ConnectAIModel(payload, os.Getenv("HUGGINGFACE_TOKEN"))
+			result, err := connector.ConnectAIModel(context.Background(), payload, os.Getenv("HUGGINGFACE_TOKEN"))
 			Expect(err).ShouldNot(HaveOccurred())
 			Expect(result).Should(Equal(expected))
 		})
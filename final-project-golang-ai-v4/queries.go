@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"a21hc3NpZ25tZW50/tableqa"
+)
+
+// readQueries membaca satu pertanyaan per baris dari r (-queries). Baris
+// kosong dan baris yang diawali "#" dilewati sehingga file pertanyaan bisa
+// diberi komentar. Urutan pertanyaan sama dengan urutan di file.
+func readQueries(r io.Reader) ([]string, error) {
+	var queries []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		queries = append(queries, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return queries, nil
+}
+
+// readQueriesFile sama dengan readQueries, tetapi membaca dari file di path.
+func readQueriesFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return readQueries(file)
+}
+
+// runQueries menjawab setiap pertanyaan di queries lalu menulis pertanyaan
+// dan jawabannya ke out sesuai urutan. Pada mode qa semua pertanyaan dikirim
+// bersamaan lewat ConnectAIModelBatchWithProgress; mode lain, dan -chunk-size,
+// menjawabnya satu per satu. Seperti repl, pertanyaan yang gagal dicatat dan
+// dilaporkan di akhir tanpa menghentikan pertanyaan lain. Setelah Ctrl-C,
+// jawaban yang sudah diterima tetap ditulis sebelum error dari ctx dikembalikan.
+func (a *app) runQueries(ctx context.Context, queries []string, out io.Writer) error {
+	answers := make([]fmt.Stringer, len(queries))
+	errs := make([]error, len(queries))
+	if a.mode == modeQA && a.chunkSize == 0 {
+		for _, query := range queries {
+			a.checkQuery(query)
+		}
+		responses, err := a.connector.ConnectAIModelBatchWithProgress(ctx, a.qaData(), queries, a.token, a.progress)
+		var batchErr *tableqa.BatchError
+		if err != nil && !errors.As(err, &batchErr) {
+			return err
+		}
+		for i, response := range responses {
+			if batchErr != nil && batchErr.Errors[i] != nil {
+				errs[i] = batchErr.Errors[i]
+				continue
+			}
+			answers[i] = a.finish(response)
+		}
+	} else {
+		for i, query := range queries {
+			if ctx.Err() != nil {
+				break
+			}
+			answers[i], errs[i] = a.answer(ctx, query)
+			if a.progress != nil {
+				a.progress(i+1, len(queries))
+			}
+		}
+	}
+	// Ctrl-C menghentikan semua pertanyaan, tetapi jawaban yang sudah diterima
+	// tetap ditulis agar tidak perlu dibayar ulang
+	cancelled := ctx.Err()
+
+	failed := &queriesFailedError{}
+	for i, query := range queries {
+		// Lewati pertanyaan yang belum dikirim atau terhenti karena Ctrl-C.
+		// Pertanyaan yang gagal tetap berisi jawaban kosong di sebagian mode
+		// (misal QAAnswer{} di extractive-qa), jadi errs yang diperiksa
+		if cancelled != nil && (errors.Is(errs[i], context.Canceled) || answers[i] == nil && errs[i] == nil) {
+			continue
+		}
+		// Pertanyaan hanya ditulis di format teks agar output JSON tetap
+		// satu objek per baris
+		if a.template == nil && a.format == formatText {
+			fmt.Fprintf(out, "Q: %s\n", query)
+		}
+		if errs[i] != nil {
+			log.Printf("Error answering query %q: %v", query, errs[i])
+			failed.count++
+			failed.last = errs[i]
+			continue
+		}
+		if err := a.write(query, answers[i], out); err != nil {
+			return err
+		}
+	}
+
+	if cancelled != nil {
+		return cancelled
+	}
+	if failed.count > 0 {
+		return failed
+	}
+	return nil
+}
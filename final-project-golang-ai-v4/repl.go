@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+// prompt ditampilkan sebelum setiap pertanyaan dibaca.
+const prompt = "Can I Help You ? : "
+
+// errQueriesFailed dikembalikan oleh repl ketika ada pertanyaan yang gagal
+// dijawab, agar exit code tetap bukan nol untuk skrip yang memakai output
+// program ini.
+var errQueriesFailed = errors.New("one or more queries failed")
+
+// queriesFailedError adalah errQueriesFailed beserta error terakhir, sehingga
+// exitCode bisa memakai jenis kegagalannya (misal jaringan atau token).
+type queriesFailedError struct {
+	count int
+	last  error
+}
+
+func (e *queriesFailedError) Error() string {
+	return fmt.Sprintf("%v (%d failed, last: %v)", errQueriesFailed, e.count, e.last)
+}
+
+func (e *queriesFailedError) Unwrap() error {
+	return e.last
+}
+
+func (e *queriesFailedError) Is(target error) bool {
+	return target == errQueriesFailed
+}
+
+// repl membaca pertanyaan dari in baris per baris dan menulis jawabannya ke
+// out sampai pengguna mengetik "exit", input habis (Ctrl-D), atau ctx
+// dibatalkan (misal Ctrl-C). Pembatalan juga menghentikan permintaan yang
+// sedang berjalan, dan repl mengembalikan ctx.Err().
+func (a *app) repl(ctx context.Context, in io.Reader, out io.Writer) error {
+	// Baca input di goroutine terpisah agar loop tetap bisa berhenti ketika
+	// ctx dibatalkan saat sedang menunggu pengguna mengetik
+	lines := make(chan string)
+	var scanErr error
+	go func() {
+		defer close(lines)
+		// Baca pertanyaan baris per baris agar query yang mengandung spasi tetap utuh
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		scanErr = scanner.Err()
+	}()
+
+	// failed mencatat pertanyaan yang gagal dan error terakhirnya
+	failed := &queriesFailedError{}
+	processed := 0
+	for {
+		fmt.Fprint(out, prompt)
+
+		var line string
+		ok := false
+		select {
+		case <-ctx.Done():
+		case line, ok = <-lines:
+		}
+		if err := ctx.Err(); err != nil {
+			fmt.Fprintln(out)
+			return err
+		}
+		if !ok {
+			// EOF (Ctrl-D) atau error saat membaca input: keluar dari loop
+			fmt.Fprintln(out)
+			if scanErr != nil {
+				return fmt.Errorf("reading query: %w", scanErr)
+			}
+			break
+		}
+
+		// Ambil input query dari pengguna
+		query := strings.TrimSpace(line)
+		if query == "" {
+			continue
+		}
+		if query == "exit" {
+			break
+		}
+
+		// Jawab pertanyaan sesuai mode yang dipilih
+		err := a.ask(ctx, query, out)
+		if err != nil {
+			// Permintaan dibatalkan karena Ctrl-C: hentikan loop
+			if ctx.Err() != nil {
+				fmt.Fprintln(out)
+				return ctx.Err()
+			}
+			var writeErr *outputError
+			if errors.As(err, &writeErr) {
+				return writeErr.err
+			}
+			// Jika terjadi error saat menjawab, log error dan lanjut ke pertanyaan berikutnya
+			log.Printf("Error answering query: %v", err)
+			failed.count++
+			failed.last = err
+		}
+
+		// Jumlah pertanyaan di repl tidak diketahui di awal, jadi total 0
+		processed++
+		if a.progress != nil {
+			a.progress(processed, 0)
+		}
+	}
+
+	if failed.count > 0 {
+		return failed
+	}
+	return nil
+}
+
+// outputError menandai kegagalan menulis jawaban (ke out atau file -out),
+// yang berbeda dari kegagalan menjawab pertanyaan: repl langsung berhenti.
+type outputError struct {
+	err error
+}
+
+func (e *outputError) Error() string {
+	return e.err.Error()
+}
+
+func (e *outputError) Unwrap() error {
+	return e.err
+}
+
+// ask menjawab satu pertanyaan, menulis jawabannya ke out dalam format yang
+// diminta, dan mencatatnya ke file -out jika ada.
+func (a *app) ask(ctx context.Context, query string, out io.Writer) error {
+	answer, err := a.answer(ctx, query)
+	if err != nil {
+		return err
+	}
+	return a.write(query, answer, out)
+}
+
+// write menulis answer ke out dan mencatatnya ke file -out jika ada.
+// Kegagalannya selalu berupa *outputError.
+func (a *app) write(query string, answer fmt.Stringer, out io.Writer) error {
+	// Cetak jawaban dengan template dari -template, atau dalam format yang diminta
+	if a.template != nil {
+		if err := writeTemplate(out, answer, a.template); err != nil {
+			return &outputError{fmt.Errorf("writing response with -template: %w", err)}
+		}
+	} else if err := writeResponse(out, answer, a.format, a.pretty, a.maxCells); err != nil {
+		return &outputError{fmt.Errorf("writing response: %w", err)}
+	}
+	if a.results != nil {
+		if err := a.results.Write(query, answer); err != nil {
+			return &outputError{fmt.Errorf("writing results file: %w", err)}
+		}
+	}
+	return nil
+}
+
+// start menjawab query lalu selesai jika query diberikan lewat -query, atau
+// menjalankan repl interaktif yang membaca pertanyaan dari in jika kosong.
+func (a *app) start(ctx context.Context, query string, in io.Reader, out io.Writer) error {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return a.repl(ctx, in, out)
+	}
+	return a.ask(ctx, query, out)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+
+	"a21hc3NpZ25tZW50/tableqa"
+)
+
+// resultsHeader adalah baris header file hasil yang ditulis lewat -out.
+var resultsHeader = []string{"query", "answer", "aggregator"}
+
+// resultWriter menambahkan satu baris query,answer,aggregator ke file CSV
+// untuk setiap pertanyaan yang dijawab.
+type resultWriter struct {
+	file *os.File
+	csv  *csv.Writer
+}
+
+// openResults membuka file CSV di path untuk ditambahi hasil. File baru
+// (atau yang masih kosong) diawali dengan baris header.
+func openResults(path string) (*resultWriter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	w := &resultWriter{file: file, csv: csv.NewWriter(file)}
+	if info.Size() == 0 {
+		if err := w.writeRow(resultsHeader); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// Write menambahkan jawaban untuk query. Jawaban selain tableqa.Response
+// (misal hasil klasifikasi) ditulis sebagai teks dengan aggregator kosong.
+func (w *resultWriter) Write(query string, answer fmt.Stringer) error {
+	row := []string{query, answer.String(), ""}
+	if r, ok := answer.(tableqa.Response); ok {
+		row = []string{query, r.Answer, r.Aggregator}
+	}
+	return w.writeRow(row)
+}
+
+// writeRow menulis satu baris lalu langsung flush, agar hasil tidak hilang
+// jika program berhenti tiba-tiba.
+func (w *resultWriter) writeRow(row []string) error {
+	if err := w.csv.Write(row); err != nil {
+		return err
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// Close menutup file hasil.
+func (w *resultWriter) Close() error {
+	return w.file.Close()
+}
@@ -0,0 +1,102 @@
+package tableqa
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultConcurrency adalah jumlah permintaan yang dikirim bersamaan oleh
+// ConnectAIModelBatch ketika AIModelConnector.Concurrency tidak diisi.
+const DefaultConcurrency = 4
+
+// WithConcurrency mengatur jumlah permintaan yang dikirim bersamaan oleh
+// ConnectAIModelBatch.
+func WithConcurrency(n int) Option {
+	return func(c *AIModelConnector) {
+		c.Concurrency = n
+	}
+}
+
+// ConnectAIModelBatch menanyakan setiap query terhadap tabel yang sama secara
+// bersamaan, paling banyak Concurrency permintaan sekaligus. Urutan hasil
+// sama dengan urutan queries. Jika ada query yang gagal, hasil query lain
+// tetap dikembalikan bersama *BatchError yang mencatat error per query.
+// Setelah ctx dibatalkan, query yang belum dikirim tidak dikirim lagi dan
+// dicatat di *BatchError dengan ctx.Err(), sehingga errors.Is(err,
+// context.Canceled) bernilai true dan jawaban yang sudah diterima tetap ada.
+func (c *AIModelConnector) ConnectAIModelBatch(ctx context.Context, table map[string][]string, queries []string, token string) ([]Response, error) {
+	return c.ConnectAIModelBatchWithProgress(ctx, table, queries, token, nil)
+}
+
+// ProgressFunc dipanggil setiap kali satu query selesai, baik berhasil maupun
+// gagal, dengan jumlah query yang sudah selesai dan jumlah seluruhnya.
+type ProgressFunc func(done, total int)
+
+// ConnectAIModelBatchWithProgress sama dengan ConnectAIModelBatch, tetapi
+// memanggil progress (jika tidak nil) setelah setiap query selesai. Panggilan
+// progress tidak pernah bersamaan, sehingga progress tidak perlu memakai lock.
+func (c *AIModelConnector) ConnectAIModelBatchWithProgress(ctx context.Context, table map[string][]string, queries []string, token string, progress ProgressFunc) ([]Response, error) {
+	workers := c.Concurrency
+	if workers <= 0 {
+		workers = DefaultConcurrency
+	}
+	if workers > len(queries) {
+		workers = len(queries)
+	}
+
+	results := make([]Response, len(queries))
+	errs := make([]error, len(queries))
+
+	// Setiap worker mengambil indeks query dari channel dan menulis hasilnya
+	// ke posisi yang sama, sehingga urutan tetap terjaga tanpa lock
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	// mu menjaga done dan memastikan progress dipanggil satu per satu
+	var mu sync.Mutex
+	done := 0
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// ctx bisa dibatalkan setelah query ini diambil dari channel
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				results[i], errs[i] = c.ConnectAIModel(ctx, Inputs{Table: table, Query: queries[i]}, token)
+				if progress != nil {
+					mu.Lock()
+					done++
+					progress(done, len(queries))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	// Berhenti membagikan query begitu ctx dibatalkan
+	sent := 0
+dispatch:
+	for ; sent < len(queries); sent++ {
+		select {
+		case jobs <- sent:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Query yang tidak sempat dibagikan dicatat dengan error dari ctx
+	for i := sent; i < len(queries); i++ {
+		errs[i] = ctx.Err()
+	}
+
+	// Kumpulkan error agar satu query yang gagal tidak menghilangkan yang lain
+	for _, err := range errs {
+		if err != nil {
+			return results, &BatchError{Errors: errs}
+		}
+	}
+	return results, nil
+}
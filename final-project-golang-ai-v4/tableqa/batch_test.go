@@ -0,0 +1,182 @@
+package tableqa_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConnectAIModelBatch", func() {
+	var (
+		server      *httptest.Server
+		mu          sync.Mutex
+		inFlight    int
+		maxInFlight int
+	)
+
+	table := map[string][]string{"Name": {"John", "Jane"}, "Age": {"30", "25"}}
+
+	BeforeEach(func() {
+		inFlight, maxInFlight = 0, 0
+		// Server menjawab dengan query itu sendiri, dan membalas 400 untuk
+		// query "fail", sambil mencatat jumlah permintaan yang berjalan bersamaan
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}()
+
+			var inputs tableqa.Inputs
+			Expect(json.NewDecoder(r.Body).Decode(&inputs)).Should(Succeed())
+			time.Sleep(20 * time.Millisecond)
+
+			if inputs.Query == "fail" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error": "bad query"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(tableqa.Response{Answer: inputs.Query})
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("returns answers in the order of the queries and bounds concurrency", func() {
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithHTTPClient(server.Client()),
+			tableqa.WithBaseURL(server.URL),
+			tableqa.WithConcurrency(3),
+		)
+		queries := []string{"q0", "q1", "q2", "q3", "q4", "q5", "q6", "q7", "q8", "q9"}
+
+		results, err := connector.ConnectAIModelBatch(context.Background(), table, queries, "token")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(results).Should(HaveLen(len(queries)))
+		for i, query := range queries {
+			Expect(results[i].Answer).Should(Equal(query))
+		}
+		Expect(maxInFlight).Should(BeNumerically(">", 1))
+		Expect(maxInFlight).Should(BeNumerically("<=", 3))
+	})
+
+	It("keeps the other answers when some queries fail", func() {
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithHTTPClient(server.Client()),
+			tableqa.WithBaseURL(server.URL),
+		)
+		queries := []string{"q0", "fail", "q2", "", "q4"}
+
+		results, err := connector.ConnectAIModelBatch(context.Background(), table, queries, "token")
+		Expect(err).Should(HaveOccurred())
+
+		var batchErr *tableqa.BatchError
+		Expect(errors.As(err, &batchErr)).Should(BeTrue())
+		Expect(batchErr.Failed()).Should(Equal(2))
+		Expect(batchErr.Errors[0]).ShouldNot(HaveOccurred())
+		Expect(batchErr.Errors[3]).Should(MatchError(tableqa.ErrEmptyQuery))
+
+		var apiErr *tableqa.APIError
+		Expect(errors.As(batchErr.Errors[1], &apiErr)).Should(BeTrue())
+		Expect(apiErr.StatusCode).Should(Equal(http.StatusBadRequest))
+
+		// Is dan As dipanggil langsung agar tidak bergantung pada dukungan
+		// Unwrap() []error di Go 1.20
+		Expect(batchErr.Is(tableqa.ErrEmptyQuery)).Should(BeTrue())
+		Expect(batchErr.Is(context.Canceled)).Should(BeFalse())
+		apiErr = nil
+		Expect(batchErr.As(&apiErr)).Should(BeTrue())
+		Expect(apiErr.StatusCode).Should(Equal(http.StatusBadRequest))
+
+		Expect(results[0].Answer).Should(Equal("q0"))
+		Expect(results[1]).Should(Equal(tableqa.Response{}))
+		Expect(results[2].Answer).Should(Equal("q2"))
+		Expect(results[4].Answer).Should(Equal("q4"))
+	})
+
+	It("reports progress once per query, including failed ones", func() {
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithHTTPClient(server.Client()),
+			tableqa.WithBaseURL(server.URL),
+			tableqa.WithConcurrency(3),
+		)
+		queries := []string{"q0", "fail", "q2", "q3", "q4"}
+
+		// progress tidak dipanggil bersamaan, jadi tidak perlu lock di sini
+		var calls [][2]int
+		_, err := connector.ConnectAIModelBatchWithProgress(context.Background(), table, queries, "token", func(done, total int) {
+			calls = append(calls, [2]int{done, total})
+		})
+		Expect(err).Should(HaveOccurred())
+		Expect(calls).Should(Equal([][2]int{{1, 5}, {2, 5}, {3, 5}, {4, 5}, {5, 5}}))
+	})
+
+	It("stops sending queries once the context is cancelled", func() {
+		var sent int
+		client := DoerFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			sent++
+			mu.Unlock()
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{"answer": "30"}`))}, nil
+		})
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(client), tableqa.WithConcurrency(1))
+		queries := []string{"q0", "q1", "q2", "q3", "q4"}
+
+		// Batalkan ctx segera setelah jawaban pertama diterima
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		results, err := connector.ConnectAIModelBatchWithProgress(ctx, table, queries, "token", func(done, total int) {
+			cancel()
+		})
+
+		Expect(sent).Should(Equal(1))
+		Expect(results).Should(HaveLen(5))
+		Expect(results[0].Answer).Should(Equal("30"))
+		Expect(errors.Is(err, context.Canceled)).Should(BeTrue())
+
+		var batchErr *tableqa.BatchError
+		Expect(errors.As(err, &batchErr)).Should(BeTrue())
+		Expect(batchErr.Errors[0]).ShouldNot(HaveOccurred())
+		for _, queryErr := range batchErr.Errors[1:] {
+			Expect(queryErr).Should(MatchError(context.Canceled))
+		}
+	})
+
+	It("sends nothing when the context is already cancelled", func() {
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(server.Client()), tableqa.WithBaseURL(server.URL))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := connector.ConnectAIModelBatch(ctx, table, []string{"q0", "q1"}, "token")
+		Expect(errors.Is(err, context.Canceled)).Should(BeTrue())
+		Expect(maxInFlight).Should(BeZero())
+	})
+
+	It("returns no results for no queries", func() {
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(server.Client()), tableqa.WithBaseURL(server.URL))
+
+		results, err := connector.ConnectAIModelBatch(context.Background(), table, nil, "token")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(results).Should(BeEmpty())
+	})
+})
@@ -0,0 +1,109 @@
+package tableqa
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+)
+
+// DefaultCacheSize adalah jumlah jawaban yang disimpan cache ketika
+// AIModelConnector.CacheSize tidak diisi.
+const DefaultCacheSize = 128
+
+// WithCache mengaktifkan cache jawaban dengan kapasitas size. Nilai <= 0
+// berarti DefaultCacheSize.
+func WithCache(size int) Option {
+	return func(c *AIModelConnector) {
+		c.EnableCache = true
+		c.CacheSize = size
+	}
+}
+
+// cacheKey menghitung hash dari URL model dan body permintaan. Body sudah
+// berisi seluruh tabel dan query (key map diurutkan oleh json.Marshal),
+// sehingga tabel berbeda dengan query yang sama menghasilkan key berbeda.
+func (c *AIModelConnector) cacheKey(reqBody []byte) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write([]byte(c.ModelURL()))
+	h.Write([]byte{0})
+	h.Write(reqBody)
+
+	var key [sha256.Size]byte
+	copy(key[:], h.Sum(nil))
+	return key
+}
+
+// responseCache menyimpan jawaban terakhir dengan eviction LRU. Aman dipakai
+// dari beberapa goroutine sekaligus.
+type responseCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // elemen terdepan adalah yang paling baru dipakai
+	entries map[[sha256.Size]byte]*list.Element
+}
+
+// cacheEntry adalah isi satu elemen pada responseCache.order.
+type cacheEntry struct {
+	key      [sha256.Size]byte
+	response Response
+}
+
+func newResponseCache(size int) *responseCache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &responseCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[[sha256.Size]byte]*list.Element),
+	}
+}
+
+// get mengembalikan jawaban untuk key dan menandainya sebagai paling baru dipakai.
+func (rc *responseCache) get(key [sha256.Size]byte) (Response, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	elem, ok := rc.entries[key]
+	if !ok {
+		return Response{}, false
+	}
+	rc.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).response, true
+}
+
+// put menyimpan jawaban untuk key, membuang entry yang paling lama tidak
+// dipakai jika kapasitas terlampaui.
+func (rc *responseCache) put(key [sha256.Size]byte, response Response) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if elem, ok := rc.entries[key]; ok {
+		elem.Value.(*cacheEntry).response = response
+		rc.order.MoveToFront(elem)
+		return
+	}
+
+	rc.entries[key] = rc.order.PushFront(&cacheEntry{key: key, response: response})
+	for rc.order.Len() > rc.size {
+		oldest := rc.order.Back()
+		rc.order.Remove(oldest)
+		delete(rc.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// responseCache mengembalikan cache milik connector, membuatnya saat pertama
+// kali dibutuhkan. Nil jika EnableCache tidak diset.
+func (c *AIModelConnector) responseCache() *responseCache {
+	if !c.EnableCache {
+		return nil
+	}
+
+	state := c.shared()
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if state.cache == nil {
+		state.cache = newResponseCache(c.CacheSize)
+	}
+	return state.cache
+}
@@ -0,0 +1,112 @@
+package tableqa_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Response cache", func() {
+	var (
+		calls  int
+		client tableqa.HTTPDoer
+	)
+
+	table := map[string][]string{"Name": {"John", "Jane"}, "Age": {"30", "25"}}
+
+	BeforeEach(func() {
+		calls = 0
+		client = DoerFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"answer": "30"}`)),
+			}, nil
+		})
+	})
+
+	ask := func(connector *tableqa.AIModelConnector, table map[string][]string, query string) tableqa.Response {
+		resp, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{Table: table, Query: query}, "token")
+		Expect(err).ShouldNot(HaveOccurred())
+		return resp
+	}
+
+	It("skips the HTTP call for a repeated query", func() {
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(client), tableqa.WithCache(0))
+
+		first := ask(connector, table, "What is the age of John?")
+		second := ask(connector, table, "What is the age of John?")
+
+		Expect(calls).Should(Equal(1))
+		Expect(second).Should(Equal(first))
+	})
+
+	It("shares the cache with a copy of the connector", func() {
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(client), tableqa.WithCache(0))
+		ask(connector, table, "What is the age of John?")
+
+		// Menyalin connector tidak menyalin lock (go vet copylocks)
+		copied := *connector
+		ask(&copied, table, "What is the age of John?")
+		Expect(calls).Should(Equal(1))
+	})
+
+	It("does not cache when disabled", func() {
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(client))
+
+		ask(connector, table, "What is the age of John?")
+		ask(connector, table, "What is the age of John?")
+
+		Expect(calls).Should(Equal(2))
+	})
+
+	It("misses when the table, query or model differ", func() {
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(client), tableqa.WithCache(0))
+
+		ask(connector, table, "What is the age of John?")
+		ask(connector, table, "What is the age of Jane?")
+		ask(connector, map[string][]string{"Name": {"John"}, "Age": {"31"}}, "What is the age of John?")
+		connector.Model = "google/tapas-large-finetuned-wtq"
+		ask(connector, table, "What is the age of John?")
+
+		Expect(calls).Should(Equal(4))
+	})
+
+	It("evicts the least recently used entry", func() {
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(client), tableqa.WithCache(2))
+
+		ask(connector, table, "a")
+		ask(connector, table, "b")
+		ask(connector, table, "a") // hit, "b" menjadi yang paling lama
+		ask(connector, table, "c") // membuang "b"
+		Expect(calls).Should(Equal(3))
+
+		ask(connector, table, "a")
+		Expect(calls).Should(Equal(3))
+		ask(connector, table, "b")
+		Expect(calls).Should(Equal(4))
+	})
+
+	It("does not cache failed requests", func() {
+		failing := DoerFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"error": "boom"}`)),
+			}, nil
+		})
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(failing), tableqa.WithCache(0))
+
+		for i := 0; i < 2; i++ {
+			_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{Table: table, Query: "q"}, "token")
+			Expect(err).Should(HaveOccurred())
+		}
+		Expect(calls).Should(Equal(2))
+	})
+})
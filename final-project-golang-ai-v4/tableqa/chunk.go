@@ -0,0 +1,131 @@
+package tableqa
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConnectAIModelChunked menanyakan query terhadap tabel yang terlalu besar
+// untuk satu permintaan. Tabel dipecah menjadi potongan berisi chunkSize
+// baris, setiap potongan ditanyakan secara berurutan, lalu jawabannya
+// digabung sesuai aggregator:
+//   - SUM dan AVERAGE dihitung ulang dari semua sel yang dipilih model
+//   - COUNT adalah jumlah semua sel yang dipilih model
+//   - NONE (pencarian biasa) memakai jawaban pertama yang tidak kosong
+//
+// Aggregator diambil dari potongan pertama yang memilih sel. Coordinates
+// pada hasil menunjuk ke baris di tabel asli. Jika chunkSize <= 0 atau tabel
+// tidak lebih panjang dari chunkSize, tabel dikirim utuh seperti ConnectAIModel.
+func (c *AIModelConnector) ConnectAIModelChunked(ctx context.Context, table map[string][]string, query, token string, chunkSize int) (Response, error) {
+	inputs := Inputs{Table: table, Query: query}
+	if err := inputs.Validate(); err != nil {
+		return Response{}, err
+	}
+
+	// Validate sudah memastikan semua kolom sama panjang
+	rows := 0
+	for _, values := range table {
+		rows = len(values)
+		break
+	}
+	if chunkSize <= 0 || rows <= chunkSize {
+		return c.ConnectAIModel(ctx, inputs, token)
+	}
+
+	var chunks []chunkResponse
+	for start := 0; start < rows; start += chunkSize {
+		end := start + chunkSize
+		if end > rows {
+			end = rows
+		}
+
+		// Potongan hanya berbagi slice dengan tabel asli tanpa menyalin data
+		window := make(map[string][]string, len(table))
+		for column, values := range table {
+			window[column] = values[start:end]
+		}
+
+		c.logf("querying rows %d-%d of %d", start, end-1, rows)
+		response, err := c.ConnectAIModel(ctx, Inputs{Table: window, Query: query}, token)
+		if err != nil {
+			return Response{}, fmt.Errorf("querying rows %d-%d: %w", start, end-1, err)
+		}
+		chunks = append(chunks, chunkResponse{offset: start, response: response})
+	}
+	return combineChunks(chunks)
+}
+
+// chunkResponse adalah jawaban untuk satu potongan tabel yang dimulai dari
+// baris offset di tabel asli.
+type chunkResponse struct {
+	offset   int
+	response Response
+}
+
+// combineChunks menggabungkan jawaban setiap potongan menjadi satu Response.
+func combineChunks(chunks []chunkResponse) (Response, error) {
+	aggregator := ""
+	for _, chunk := range chunks {
+		if len(chunk.response.Cells) > 0 {
+			aggregator = chunk.response.Aggregator
+			break
+		}
+	}
+
+	// Pencarian biasa: jawaban pertama yang ditemukan sudah cukup
+	if aggregator == "" || strings.EqualFold(aggregator, "NONE") {
+		for _, chunk := range chunks {
+			if chunk.response.Answer != "" {
+				result := chunk.response
+				result.Coordinates = shiftRows(result.Coordinates, chunk.offset)
+				return result, nil
+			}
+		}
+		return chunks[0].response, nil
+	}
+
+	result := Response{Aggregator: aggregator}
+	for _, chunk := range chunks {
+		result.Cells = append(result.Cells, chunk.response.Cells...)
+		result.Coordinates = append(result.Coordinates, shiftRows(chunk.response.Coordinates, chunk.offset)...)
+	}
+
+	switch strings.ToUpper(aggregator) {
+	case "COUNT":
+		result.Answer = strconv.Itoa(len(result.Cells))
+	case "SUM", "AVERAGE":
+		sum := 0.0
+		for _, cell := range result.Cells {
+			value, err := strconv.ParseFloat(strings.TrimSpace(cell), 64)
+			if err != nil {
+				return Response{}, fmt.Errorf("cannot combine %s over non-numeric cell %q", aggregator, cell)
+			}
+			sum += value
+		}
+		if strings.EqualFold(aggregator, "AVERAGE") {
+			sum /= float64(len(result.Cells))
+		}
+		result.Answer = strconv.FormatFloat(sum, 'f', -1, 64)
+	default:
+		return Response{}, fmt.Errorf("cannot combine answers with aggregator %q", aggregator)
+	}
+	return result, nil
+}
+
+// shiftRows mengembalikan salinan coordinates dengan indeks baris ditambah
+// offset, sehingga menunjuk ke baris di tabel asli.
+func shiftRows(coordinates [][]int, offset int) [][]int {
+	if coordinates == nil {
+		return nil
+	}
+	shifted := make([][]int, len(coordinates))
+	for i, coord := range coordinates {
+		shifted[i] = append([]int(nil), coord...)
+		if len(shifted[i]) > 0 {
+			shifted[i][0] += offset
+		}
+	}
+	return shifted
+}
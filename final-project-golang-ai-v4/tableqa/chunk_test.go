@@ -0,0 +1,124 @@
+package tableqa_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("connectAIModelChunked", func() {
+	table := map[string][]string{
+		"Appliance":          {"Refrigerator", "TV", "Fan", "Heater"},
+		"Energy_Consumption": {"1.5", "0.5", "0.25", "2"},
+	}
+
+	// connector membalas setiap potongan dengan answer dari fungsi respond
+	// sambil mencatat potongan yang dikirim
+	var windows []map[string][]string
+	connector := func(respond func(window map[string][]string) tableqa.Response) *tableqa.AIModelConnector {
+		windows = nil
+		return tableqa.NewAIModelConnector(tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+			var inputs tableqa.Inputs
+			Expect(json.NewDecoder(req.Body).Decode(&inputs)).Should(Succeed())
+			windows = append(windows, inputs.Table)
+
+			body, err := json.Marshal(respond(inputs.Table))
+			Expect(err).ShouldNot(HaveOccurred())
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(string(body)))}, nil
+		})))
+	}
+
+	It("adds up a SUM across chunks", func() {
+		c := connector(func(window map[string][]string) tableqa.Response {
+			return tableqa.Response{
+				Answer:      "SUM > " + strings.Join(window["Energy_Consumption"], ", "),
+				Coordinates: [][]int{{0, 1}, {1, 1}},
+				Cells:       window["Energy_Consumption"],
+				Aggregator:  "SUM",
+			}
+		})
+
+		result, err := c.ConnectAIModelChunked(context.Background(), table, "What is the total energy consumption?", "token", 2)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(windows).Should(Equal([]map[string][]string{
+			{"Appliance": {"Refrigerator", "TV"}, "Energy_Consumption": {"1.5", "0.5"}},
+			{"Appliance": {"Fan", "Heater"}, "Energy_Consumption": {"0.25", "2"}},
+		}))
+		Expect(result).Should(Equal(tableqa.Response{
+			Answer:      "4.25",
+			Coordinates: [][]int{{0, 1}, {1, 1}, {2, 1}, {3, 1}},
+			Cells:       []string{"1.5", "0.5", "0.25", "2"},
+			Aggregator:  "SUM",
+		}))
+	})
+
+	It("returns the first lookup answer with coordinates in the original table", func() {
+		c := connector(func(window map[string][]string) tableqa.Response {
+			if window["Appliance"][1] != "Heater" {
+				return tableqa.Response{Answer: "", Aggregator: "NONE"}
+			}
+			return tableqa.Response{Answer: "Heater", Coordinates: [][]int{{1, 0}}, Cells: []string{"Heater"}, Aggregator: "NONE"}
+		})
+
+		result, err := c.ConnectAIModelChunked(context.Background(), table, "Which appliance uses 2 kWh?", "token", 2)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(windows).Should(HaveLen(2))
+		Expect(result).Should(Equal(tableqa.Response{
+			Answer:      "Heater",
+			Coordinates: [][]int{{3, 0}},
+			Cells:       []string{"Heater"},
+			Aggregator:  "NONE",
+		}))
+	})
+
+	DescribeTable("combines other aggregators",
+		func(aggregator, answer string) {
+			c := connector(func(window map[string][]string) tableqa.Response {
+				return tableqa.Response{Cells: window["Energy_Consumption"], Aggregator: aggregator}
+			})
+
+			result, err := c.ConnectAIModelChunked(context.Background(), table, "query", "token", 3)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.Answer).Should(Equal(answer))
+		},
+		Entry("COUNT", "COUNT", "4"),
+		Entry("AVERAGE over all cells, not the average of averages", "AVERAGE", "1.0625"),
+	)
+
+	It("rejects a SUM over text cells", func() {
+		c := connector(func(window map[string][]string) tableqa.Response {
+			return tableqa.Response{Cells: window["Appliance"], Aggregator: "SUM"}
+		})
+
+		_, err := c.ConnectAIModelChunked(context.Background(), table, "query", "token", 2)
+		Expect(err).Should(MatchError(`cannot combine SUM over non-numeric cell "Refrigerator"`))
+	})
+
+	It("sends a table that fits in one chunk as is", func() {
+		c := connector(func(window map[string][]string) tableqa.Response {
+			return tableqa.Response{Answer: "Heater"}
+		})
+
+		result, err := c.ConnectAIModelChunked(context.Background(), table, "query", "token", 4)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(windows).Should(Equal([]map[string][]string{table}))
+		Expect(result.Answer).Should(Equal("Heater"))
+	})
+
+	It("validates the whole table before splitting it", func() {
+		c := connector(func(window map[string][]string) tableqa.Response {
+			return tableqa.Response{Answer: "unused"}
+		})
+
+		_, err := c.ConnectAIModelChunked(context.Background(), map[string][]string{"A": {"1", "2"}, "B": {"1"}}, "query", "token", 1)
+		Expect(err).Should(MatchError(tableqa.ErrRaggedTable))
+		Expect(windows).Should(BeEmpty())
+	})
+})
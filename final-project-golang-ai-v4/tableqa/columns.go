@@ -0,0 +1,178 @@
+package tableqa
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnknownColumn dikembalikan ketika kolom yang diminta tidak ada di tabel.
+var ErrUnknownColumn = errors.New("unknown column")
+
+// SelectColumns mengembalikan tabel baru yang hanya berisi kolom cols. Kolom
+// yang tidak ada di table dikembalikan sebagai error yang membungkus
+// ErrUnknownColumn. Gunakan SelectTableColumns untuk mempertahankan urutan cols.
+func SelectColumns(table map[string][]string, cols []string) (map[string][]string, error) {
+	selected := make(map[string][]string, len(cols))
+	for _, col := range cols {
+		values, ok := table[col]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, col)
+		}
+		selected[col] = values
+	}
+	return selected, nil
+}
+
+// ResolveColumn mencari kolom name di columns dan mengembalikan nama kolom
+// seperti yang tertulis di header. Jika caseInsensitive, "name" juga cocok
+// dengan kolom "Name" dan sebaliknya, karena pengguna jarang ingat huruf
+// besar-kecil header; kolom yang sama persis tetap didahulukan, dan name yang
+// cocok dengan lebih dari satu kolom ditolak. Kolom yang tidak ada
+// dikembalikan sebagai error yang membungkus ErrUnknownColumn.
+func ResolveColumn(columns []string, name string, caseInsensitive bool) (string, error) {
+	var matched []string
+	for _, column := range columns {
+		if column == name {
+			return column, nil
+		}
+		if caseInsensitive && strings.EqualFold(column, name) {
+			matched = append(matched, column)
+		}
+	}
+
+	switch len(matched) {
+	case 0:
+		return "", fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+	case 1:
+		return matched[0], nil
+	default:
+		return "", fmt.Errorf("column %q is ambiguous: it matches %s", name, strings.Join(matched, ", "))
+	}
+}
+
+// ResolveColumns sama dengan ResolveColumn untuk setiap nama di names, dengan
+// urutan hasil mengikuti names. Hasilnya bisa langsung diberikan ke
+// SelectColumns atau SelectTableColumns.
+func ResolveColumns(columns, names []string, caseInsensitive bool) ([]string, error) {
+	resolved := make([]string, len(names))
+	for i, name := range names {
+		column, err := ResolveColumn(columns, name, caseInsensitive)
+		if err != nil {
+			return nil, err
+		}
+		resolved[i] = column
+	}
+	return resolved, nil
+}
+
+// RowNumberColumn adalah nama kolom yang ditambahkan AddRowNumbers.
+const RowNumberColumn = "row"
+
+// AddRowNumbers mengembalikan salinan t dengan kolom RowNumberColumn berisi
+// nomor baris mulai dari 1 di posisi pertama, agar model bisa menjawab
+// pertanyaan seperti "what is in row 3?". Kolom itu hanya dikirim sebagai
+// kolom pertama jika connector memakai WithColumnOrder(t.Columns); tanpa itu
+// kolom dikirim urut nama. Tabel yang sudah memiliki kolom dengan nama itu
+// ditolak. t tidak diubah.
+func AddRowNumbers(t Table) (Table, error) {
+	if _, ok := t.Data[RowNumberColumn]; ok {
+		return Table{}, fmt.Errorf("table already has a %q column", RowNumberColumn)
+	}
+
+	rows := make([]string, rowCount(t))
+	for i := range rows {
+		rows[i] = strconv.Itoa(i + 1)
+	}
+
+	data := make(map[string][]string, len(t.Data)+1)
+	for column, values := range t.Data {
+		data[column] = values
+	}
+	data[RowNumberColumn] = rows
+	return Table{Columns: append([]string{RowNumberColumn}, t.Columns...), Data: data}, nil
+}
+
+// RenameColumns mengembalikan salinan table dengan kolom diganti namanya
+// sesuai mapping (nama lama ke nama baru), misal {"Total_Sales_USD": "Sales"}
+// agar header CSV lebih mudah dipahami model. Kolom lama yang tidak ada
+// dikembalikan sebagai error yang membungkus ErrUnknownColumn, dan nama baru
+// yang bentrok dengan kolom lain juga ditolak. Map asli tidak diubah.
+func RenameColumns(table map[string][]string, mapping map[string]string) (map[string][]string, error) {
+	for old := range mapping {
+		if _, ok := table[old]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, old)
+		}
+	}
+
+	renamed := make(map[string][]string, len(table))
+	for col, values := range table {
+		name := col
+		if newName, ok := mapping[col]; ok {
+			name = newName
+		}
+		if _, ok := renamed[name]; ok {
+			return nil, fmt.Errorf("renaming columns: duplicate column %q", name)
+		}
+		renamed[name] = values
+	}
+	return renamed, nil
+}
+
+// RenameTableColumns sama dengan RenameColumns untuk Table, dengan urutan
+// kolom tetap sama seperti t.
+func RenameTableColumns(t Table, mapping map[string]string) (Table, error) {
+	data, err := RenameColumns(t.Data, mapping)
+	if err != nil {
+		return Table{}, err
+	}
+
+	columns := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		if newName, ok := mapping[col]; ok {
+			col = newName
+		}
+		columns[i] = col
+	}
+	return Table{Columns: columns, Data: data}, nil
+}
+
+// SelectTableColumns sama dengan SelectColumns untuk Table, dengan urutan
+// kolom hasil mengikuti cols. Kolom yang disebut lebih dari sekali hanya
+// diambil sekali.
+func SelectTableColumns(t Table, cols []string) (Table, error) {
+	data, err := SelectColumns(t.Data, cols)
+	if err != nil {
+		return Table{}, err
+	}
+
+	columns := make([]string, 0, len(data))
+	seen := make(map[string]bool, len(data))
+	for _, col := range cols {
+		if !seen[col] {
+			seen[col] = true
+			columns = append(columns, col)
+		}
+	}
+	return Table{Columns: columns, Data: data}, nil
+}
+
+// MatchColumns mengembalikan kolom di columns yang disebut di query, misal
+// "Energy_Consumption" untuk "total energy consumption". Nama kolom dicocokkan
+// tanpa membedakan huruf besar, dan '_' juga boleh ditulis sebagai spasi.
+// Urutan hasil mengikuti columns. Model tetap memilih kolomnya sendiri; hasil
+// ini hanya membantu pengguna melihat apakah pertanyaannya menyebut kolom
+// yang benar.
+func MatchColumns(query string, columns []string) []string {
+	lower := strings.ToLower(query)
+
+	var matched []string
+	for _, column := range columns {
+		name := strings.ToLower(column)
+		if strings.Contains(lower, name) || strings.Contains(lower, strings.ReplaceAll(name, "_", " ")) {
+			matched = append(matched, column)
+		}
+	}
+	return matched
+}
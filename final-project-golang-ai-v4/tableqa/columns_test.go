@@ -0,0 +1,191 @@
+package tableqa_test
+
+import (
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("selectColumns", func() {
+	table := map[string][]string{
+		"Name": {"John", "Jane"},
+		"Age":  {"30", "25"},
+		"City": {"Jakarta", "Bandung"},
+	}
+
+	It("keeps only the requested columns", func() {
+		selected, err := tableqa.SelectColumns(table, []string{"Name", "City"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(selected).Should(Equal(map[string][]string{
+			"Name": {"John", "Jane"},
+			"City": {"Jakarta", "Bandung"},
+		}))
+	})
+
+	It("rejects an unknown column", func() {
+		_, err := tableqa.SelectColumns(table, []string{"Name", "Country"})
+		Expect(err).Should(MatchError(tableqa.ErrUnknownColumn))
+		Expect(err).Should(MatchError(`unknown column: "Country"`))
+	})
+
+	It("does not modify the original table", func() {
+		_, err := tableqa.SelectColumns(table, []string{"Age"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(table).Should(HaveLen(3))
+	})
+
+	Describe("selectTableColumns", func() {
+		t := tableqa.Table{Columns: []string{"Name", "Age", "City"}, Data: table}
+
+		It("orders the columns as requested", func() {
+			selected, err := tableqa.SelectTableColumns(t, []string{"City", "Name"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(selected.Columns).Should(Equal([]string{"City", "Name"}))
+			Expect(selected.Data).Should(HaveLen(2))
+		})
+
+		It("lists a repeated column once", func() {
+			selected, err := tableqa.SelectTableColumns(t, []string{"Age", "Name", "Age"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(selected.Columns).Should(Equal([]string{"Age", "Name"}))
+		})
+
+		It("rejects an unknown column", func() {
+			_, err := tableqa.SelectTableColumns(t, []string{"Country"})
+			Expect(err).Should(MatchError(tableqa.ErrUnknownColumn))
+		})
+	})
+})
+
+var _ = Describe("resolveColumn", func() {
+	DescribeTable("matches column names ignoring case and keeps the header casing",
+		func(columns []string, name, expected string) {
+			column, err := tableqa.ResolveColumn(columns, name, true)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(column).Should(Equal(expected))
+		},
+		Entry(`"Name" against a "name" column`, []string{"name", "age"}, "Name", "name"),
+		Entry(`"name" against a "Name" column`, []string{"Name", "Age"}, "name", "Name"),
+		Entry("an exact match before a case-insensitive one", []string{"name", "Name"}, "Name", "Name"),
+	)
+
+	It("requires the exact casing when case-sensitive", func() {
+		_, err := tableqa.ResolveColumn([]string{"Name", "Age"}, "name", false)
+		Expect(err).Should(MatchError(tableqa.ErrUnknownColumn))
+
+		column, err := tableqa.ResolveColumn([]string{"Name", "Age"}, "Name", false)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(column).Should(Equal("Name"))
+	})
+
+	It("rejects a name that matches several columns", func() {
+		_, err := tableqa.ResolveColumn([]string{"name", "Name"}, "NAME", true)
+		Expect(err).Should(MatchError(`column "NAME" is ambiguous: it matches name, Name`))
+	})
+
+	It("resolves a list of columns in order for SelectTableColumns", func() {
+		t := tableqa.Table{
+			Columns: []string{"Name", "Age", "City"},
+			Data:    map[string][]string{"Name": {"John"}, "Age": {"30"}, "City": {"Jakarta"}},
+		}
+		names, err := tableqa.ResolveColumns(t.Columns, []string{"city", "NAME"}, true)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(names).Should(Equal([]string{"City", "Name"}))
+
+		selected, err := tableqa.SelectTableColumns(t, names)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(selected).Should(Equal(tableqa.Table{
+			Columns: []string{"City", "Name"},
+			Data:    map[string][]string{"City": {"Jakarta"}, "Name": {"John"}},
+		}))
+
+		_, err = tableqa.ResolveColumns(t.Columns, []string{"city", "Country"}, true)
+		Expect(err).Should(MatchError(tableqa.ErrUnknownColumn))
+	})
+})
+
+var _ = Describe("addRowNumbers", func() {
+	t := tableqa.Table{
+		Columns: []string{"Appliance", "Room"},
+		Data: map[string][]string{
+			"Appliance": {"Refrigerator", "TV", "Heater"},
+			"Room":      {"Kitchen", "Living Room", "Bedroom"},
+		},
+	}
+
+	It("prepends a 1-based row column as long as the others", func() {
+		numbered, err := tableqa.AddRowNumbers(t)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(numbered.Columns).Should(Equal([]string{tableqa.RowNumberColumn, "Appliance", "Room"}))
+		Expect(numbered.Data[tableqa.RowNumberColumn]).Should(Equal([]string{"1", "2", "3"}))
+		Expect(numbered.Data["Appliance"]).Should(Equal(t.Data["Appliance"]))
+		Expect(tableqa.Inputs{Table: numbered.Data, Query: "What is in row 2?"}.Validate()).Should(Succeed())
+	})
+
+	It("does not modify the original table", func() {
+		_, err := tableqa.AddRowNumbers(t)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(t.Columns).Should(Equal([]string{"Appliance", "Room"}))
+		Expect(t.Data).ShouldNot(HaveKey(tableqa.RowNumberColumn))
+	})
+
+	It("rejects a table that already has a row column", func() {
+		_, err := tableqa.AddRowNumbers(tableqa.Table{Columns: []string{"row"}, Data: map[string][]string{"row": {"a"}}})
+		Expect(err).Should(MatchError(`table already has a "row" column`))
+	})
+})
+
+var _ = Describe("renameColumns", func() {
+	table := map[string][]string{
+		"col_1":           {"John", "Jane"},
+		"Total_Sales_USD": {"100", "250"},
+	}
+
+	It("renames the mapped columns and keeps the others", func() {
+		renamed, err := tableqa.RenameColumns(table, map[string]string{"Total_Sales_USD": "Sales"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(renamed).Should(Equal(map[string][]string{
+			"col_1": {"John", "Jane"},
+			"Sales": {"100", "250"},
+		}))
+		Expect(table).Should(HaveKey("Total_Sales_USD"))
+	})
+
+	It("rejects an unknown source column", func() {
+		_, err := tableqa.RenameColumns(table, map[string]string{"col_2": "Name"})
+		Expect(err).Should(MatchError(tableqa.ErrUnknownColumn))
+		Expect(err).Should(MatchError(`unknown column: "col_2"`))
+	})
+
+	It("rejects a new name that clashes with another column", func() {
+		_, err := tableqa.RenameColumns(table, map[string]string{"col_1": "Total_Sales_USD"})
+		Expect(err).Should(MatchError(ContainSubstring(`duplicate column "Total_Sales_USD"`)))
+	})
+
+	It("keeps the column order of a Table", func() {
+		t := tableqa.Table{Columns: []string{"col_1", "Total_Sales_USD"}, Data: table}
+		renamed, err := tableqa.RenameTableColumns(t, map[string]string{"col_1": "Name", "Total_Sales_USD": "Sales"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(renamed.Columns).Should(Equal([]string{"Name", "Sales"}))
+		Expect(renamed.Data).Should(Equal(map[string][]string{
+			"Name":  {"John", "Jane"},
+			"Sales": {"100", "250"},
+		}))
+	})
+})
+
+var _ = Describe("matchColumns", func() {
+	columns := []string{"Appliance", "Energy_Consumption", "Room"}
+
+	DescribeTable("finds the columns mentioned in a query",
+		func(query string, expected []string) {
+			Expect(tableqa.MatchColumns(query, columns)).Should(Equal(expected))
+		},
+		Entry("exact name", "What is the total Energy_Consumption?", []string{"Energy_Consumption"}),
+		Entry("different case", "which APPLIANCE is on?", []string{"Appliance"}),
+		Entry("underscore written as a space", "total energy consumption", []string{"Energy_Consumption"}),
+		Entry("several columns in column order", "energy_consumption per room and appliance", []string{"Appliance", "Energy_Consumption", "Room"}),
+		Entry("no column", "What is the total revenue?", nil),
+	)
+})
@@ -0,0 +1,91 @@
+package tableqa
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// CompressThreshold adalah ukuran body JSON minimum (dalam byte) yang
+// dikompres dengan gzip ketika AIModelConnector.Compress diset. Body yang
+// lebih kecil dikirim apa adanya karena header gzip justru menambah ukuran.
+const CompressThreshold = 1024
+
+// WithCompression mengaktifkan kompresi gzip untuk permintaan besar dan
+// respons dari API.
+func WithCompression() Option {
+	return func(c *AIModelConnector) {
+		c.Compress = true
+	}
+}
+
+// encodeBody mengompres reqBody dengan gzip jika Compress diset dan body
+// cukup besar, lalu mengembalikan body yang dikirim beserta nilai header
+// Content-Encoding-nya (kosong jika tidak dikompres).
+func (c *AIModelConnector) encodeBody(reqBody []byte) ([]byte, string, error) {
+	if !c.Compress || len(reqBody) < CompressThreshold {
+		return reqBody, "", nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(reqBody); err != nil {
+		return nil, "", err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, "", err
+	}
+	c.logf("compressed request body from %d to %d bytes", len(reqBody), buf.Len())
+	return buf.Bytes(), "gzip", nil
+}
+
+// ErrInvalidGzip dikembalikan ketika respons mengaku gzip lewat
+// Content-Encoding tetapi body-nya tidak bisa didekompresi.
+var ErrInvalidGzip = errors.New("invalid gzip response")
+
+// decodeBody mengganti body resp dengan versi yang sudah didekompresi jika
+// server membalas dengan Content-Encoding: gzip. Body kosong, misal pada 204
+// atau error dari gateway, dibiarkan apa adanya. Header gzip yang rusak
+// dikembalikan sebagai ErrInvalidGzip beserta status HTTP, bukan io.EOF,
+// agar tidak dianggap gangguan jaringan dan diulang.
+func decodeBody(resp *http.Response) error {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return nil
+	}
+
+	br := bufio.NewReader(resp.Body)
+	if _, err := br.Peek(1); err == io.EOF {
+		resp.Header.Del("Content-Encoding")
+		return nil
+	} else if err != nil {
+		resp.Body.Close()
+		return err
+	}
+
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		resp.Body.Close()
+		return fmt.Errorf("decoding gzip response (status %d): %w: %v", resp.StatusCode, ErrInvalidGzip, err)
+	}
+	resp.Body = &gzipBody{Reader: zr, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	return nil
+}
+
+// gzipBody membaca dari gzip.Reader dan menutup body asli saat ditutup.
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
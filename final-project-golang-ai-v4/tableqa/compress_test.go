@@ -0,0 +1,167 @@
+package tableqa_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// gzipBytes mengompres s dengan gzip.
+func gzipBytes(s string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(s))
+	zw.Close()
+	return buf.Bytes()
+}
+
+var _ = Describe("Compression", func() {
+	var (
+		server          *httptest.Server
+		requestEncoding string
+		acceptEncoding  string
+	)
+
+	// bigTable cukup besar untuk melewati CompressThreshold
+	bigTable := map[string][]string{"Name": {}, "Age": {}}
+	for i := 0; i < 200; i++ {
+		bigTable["Name"] = append(bigTable["Name"], fmt.Sprintf("Person %d", i))
+		bigTable["Age"] = append(bigTable["Age"], fmt.Sprint(20+i%50))
+	}
+	smallTable := map[string][]string{"Name": {"John"}, "Age": {"30"}}
+
+	BeforeEach(func() {
+		// Server mendekompresi permintaan jika perlu, lalu memantulkan query
+		// dan jumlah baris dalam respons gzip
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestEncoding = r.Header.Get("Content-Encoding")
+			acceptEncoding = r.Header.Get("Accept-Encoding")
+
+			var body io.Reader = r.Body
+			if requestEncoding == "gzip" {
+				zr, err := gzip.NewReader(r.Body)
+				Expect(err).ShouldNot(HaveOccurred())
+				body = zr
+			}
+			var inputs tableqa.Inputs
+			Expect(json.NewDecoder(body).Decode(&inputs)).Should(Succeed())
+
+			reply, _ := json.Marshal(tableqa.Response{Answer: inputs.Query, Cells: []string{fmt.Sprint(len(inputs.Table["Name"]))}})
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(gzipBytes(string(reply)))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("round-trips a large gzipped request and response", func() {
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithHTTPClient(server.Client()),
+			tableqa.WithBaseURL(server.URL),
+			tableqa.WithCompression(),
+		)
+
+		result, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{Table: bigTable, Query: "Who is the oldest?"}, "token")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(requestEncoding).Should(Equal("gzip"))
+		Expect(acceptEncoding).Should(Equal("gzip"))
+		Expect(result).Should(Equal(tableqa.Response{Answer: "Who is the oldest?", Cells: []string{"200"}}))
+	})
+
+	It("sends small bodies uncompressed", func() {
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithHTTPClient(server.Client()),
+			tableqa.WithBaseURL(server.URL),
+			tableqa.WithCompression(),
+		)
+
+		result, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{Table: smallTable, Query: "Who is John?"}, "token")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(requestEncoding).Should(BeEmpty())
+		Expect(result.Answer).Should(Equal("Who is John?"))
+	})
+
+	It("does not compress requests unless enabled", func() {
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(server.Client()), tableqa.WithBaseURL(server.URL))
+
+		result, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{Table: bigTable, Query: "Who is the oldest?"}, "token")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(requestEncoding).Should(BeEmpty())
+		Expect(result.Cells).Should(Equal([]string{"200"}))
+	})
+
+	It("decompresses gzipped error bodies", func() {
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Header:     http.Header{"Content-Encoding": {"gzip"}},
+				Body:       ioutil.NopCloser(bytes.NewReader(gzipBytes(`{"error": "bad table"}`))),
+			}, nil
+		})))
+
+		_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{Table: smallTable, Query: "Who is John?"}, "token")
+		var apiErr *tableqa.APIError
+		Expect(errors.As(err, &apiErr)).Should(BeTrue())
+		Expect(apiErr.Body).Should(Equal(`{"error": "bad table"}`))
+	})
+
+	It("fails on a response that claims gzip but is not", func() {
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Encoding": {"gzip"}},
+				Body:       ioutil.NopCloser(strings.NewReader(`{"answer": "30"}`)),
+			}, nil
+		})))
+
+		_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{Table: smallTable, Query: "Who is John?"}, "token")
+		Expect(err).Should(MatchError(tableqa.ErrInvalidGzip))
+		Expect(err.Error()).Should(ContainSubstring("status 200"))
+	})
+
+	DescribeTable("does not retry an empty response labelled gzip",
+		func(status int, check func(error)) {
+			calls := 0
+			connector := tableqa.NewAIModelConnector(
+				tableqa.WithRetries(3, time.Millisecond),
+				tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+					calls++
+					return &http.Response{
+						StatusCode: status,
+						Header:     http.Header{"Content-Encoding": {"gzip"}},
+						Body:       ioutil.NopCloser(strings.NewReader("")),
+					}, nil
+				})),
+			)
+
+			_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{Table: smallTable, Query: "Who is John?"}, "token")
+			Expect(calls).Should(Equal(1))
+			Expect(errors.Is(err, io.EOF)).Should(BeFalse())
+			check(err)
+		},
+		Entry("a gateway error", http.StatusBadGateway, func(err error) {
+			var apiErr *tableqa.APIError
+			Expect(errors.As(err, &apiErr)).Should(BeTrue())
+			Expect(apiErr.StatusCode).Should(Equal(http.StatusBadGateway))
+		}),
+		Entry("no content", http.StatusNoContent, func(err error) {
+			Expect(err).Should(HaveOccurred())
+		}),
+	)
+})
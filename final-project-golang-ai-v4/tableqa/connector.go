@@ -0,0 +1,704 @@
+package tableqa
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+	"unicode"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultModel adalah model table-question-answering yang dipakai ketika
+// AIModelConnector.Model tidak diisi.
+const DefaultModel = "google/tapas-base-finetuned-wtq"
+
+// DefaultBaseURL adalah alamat Hugging Face Inference API yang dipakai ketika
+// AIModelConnector.BaseURL tidak diisi.
+const DefaultBaseURL = "https://api-inference.huggingface.co"
+
+// DryRunAnswer adalah jawaban yang dikembalikan ConnectAIModel ketika
+// AIModelConnector.DryRun diset.
+const DryRunAnswer = "<dry-run>"
+
+// DefaultUserAgent adalah header User-Agent yang dikirim ketika
+// AIModelConnector.UserAgent tidak diisi.
+const DefaultUserAgent = "golang-ai-tableqa/1.0"
+
+// DefaultAuthScheme adalah skema header Authorization yang diisi
+// NewAIModelConnector.
+const DefaultAuthScheme = "Bearer"
+
+// DefaultMaxPayloadBytes adalah batas ukuran body JSON ketika
+// AIModelConnector.MaxPayloadBytes tidak diisi.
+const DefaultMaxPayloadBytes = 1 << 20
+
+// DefaultMaxColumns adalah batas jumlah kolom tabel ketika
+// AIModelConnector.MaxColumns tidak diisi.
+const DefaultMaxColumns = 256
+
+// DefaultMaxWait adalah batas lama tunggu di antara pengulangan ketika
+// AIModelConnector.MaxWait tidak diisi.
+const DefaultMaxWait = 30 * time.Second
+
+// HTTPDoer adalah bagian dari *http.Client yang dibutuhkan AIModelConnector.
+// Dengan interface ini, test bisa memakai client tiruan tanpa token dan jaringan.
+type HTTPDoer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// AIModelConnector memanggil model table-question-answering di Hugging Face
+// Inference API. Gunakan NewAIModelConnector untuk membuat connector dengan
+// nilai default yang aman.
+type AIModelConnector struct {
+	Client HTTPDoer
+	// Model adalah ID model di Hugging Face Hub, misal "google/tapas-base-finetuned-wtq".
+	// Jika kosong, DefaultModel yang digunakan.
+	Model string
+	// BaseURL adalah alamat inference API tanpa garis miring di akhir, misal
+	// endpoint self-hosted. Jika kosong, DefaultBaseURL yang digunakan.
+	BaseURL string
+	// MaxRetries adalah jumlah maksimum pengulangan ketika API membalas 503
+	// karena model masih dimuat, atau ketika terjadi gangguan jaringan
+	// sementara (diulang dengan exponential backoff). Nol berarti tidak ada
+	// pengulangan.
+	MaxRetries int
+	// MaxWait membatasi lama tunggu di antara pengulangan, berapa pun
+	// estimated_time yang dikirim API. Jika nol, DefaultMaxWait yang digunakan.
+	MaxWait time.Duration
+	// MaxRows membatasi jumlah baris tabel yang dikirim ke model agar tidak
+	// melebihi batas input model. Nol berarti tanpa batas. Jumlah baris yang
+	// dibuang dilaporkan di Metrics.TruncatedRows.
+	MaxRows int
+	// MaxColumns membatasi jumlah kolom tabel. Tabel yang lebih lebar ditolak
+	// dengan *TooManyColumnsError sebelum dikirim, karena model memotong
+	// input yang terlalu panjang tanpa pesan yang jelas. Jika nol,
+	// DefaultMaxColumns yang digunakan; nilai negatif berarti tanpa batas.
+	MaxColumns int
+	// MaxPayloadBytes membatasi ukuran body JSON (sebelum dikompres) agar
+	// tabel yang terlalu besar ditolak dengan pesan yang jelas alih-alih error
+	// dari server. Jika nol, DefaultMaxPayloadBytes yang digunakan; nilai
+	// negatif berarti tanpa batas.
+	MaxPayloadBytes int
+	// Logger, jika diisi, menerima log URL model dan ukuran setiap permintaan.
+	// Jika nil, ConnectAIModel tidak menulis log apa pun.
+	Logger *log.Logger
+	// Limiter, jika diisi, ditunggu sebelum setiap permintaan dikirim.
+	// Gunakan WithRateLimit untuk membuatnya dari jumlah permintaan per menit.
+	Limiter *rate.Limiter
+	// EnableCache menyimpan jawaban di memori sehingga pertanyaan yang sama
+	// untuk tabel dan model yang sama tidak dikirim ulang ke API.
+	EnableCache bool
+	// CacheSize adalah jumlah jawaban maksimum di cache; entry yang paling
+	// lama tidak dipakai dibuang lebih dulu. Jika nol, DefaultCacheSize yang digunakan.
+	CacheSize int
+	// Concurrency adalah jumlah permintaan yang dikirim bersamaan oleh
+	// ConnectAIModelBatch. Jika nol, DefaultConcurrency yang digunakan.
+	Concurrency int
+	// Compress mengompres body permintaan yang lebih besar dari
+	// CompressThreshold dengan gzip dan meminta respons dalam gzip.
+	Compress bool
+	// DryRun membuat ConnectAIModel hanya menulis URL dan body permintaan ke
+	// Logger lalu mengembalikan DryRunAnswer tanpa memanggil API.
+	DryRun bool
+	// UserAgent dikirim sebagai header User-Agent agar permintaan mudah
+	// dikenali di log Hugging Face dan proxy. Jika kosong, DefaultUserAgent
+	// yang digunakan.
+	UserAgent string
+	// ReplayDir, jika diisi, membuat ConnectAIModel menjawab dari jawaban
+	// yang direkam di direktori ini tanpa memanggil API. Pertanyaan yang
+	// belum direkam menghasilkan error ErrNoFixture.
+	ReplayDir string
+	// RecordDir, jika diisi, menyimpan setiap jawaban yang berhasil dari API
+	// ke FixtureFile di direktori ini untuk dipakai ReplayDir, dengan key
+	// teks pertanyaan lalu model.
+	RecordDir string
+	// PayloadFormat menentukan bentuk tabel di body permintaan. Jika kosong,
+	// PayloadColumns yang digunakan.
+	PayloadFormat PayloadFormat
+	// ColumnOrder adalah urutan kolom tabel di body permintaan, biasanya
+	// Table.Columns, karena TAPAS membaca kolom sesuai urutan tersebut.
+	// Kolom yang tidak disebut menyusul secara urut nama. Jika kosong, semua
+	// kolom dikirim urut nama.
+	ColumnOrder []string
+	// Revision, jika diisi, mengunci versi model ke branch, tag, atau commit
+	// tertentu di Hub (misal "main" atau hash commit) lewat parameter
+	// ?revision= agar jawaban bisa direproduksi.
+	Revision string
+	// AuthScheme adalah skema header Authorization, sehingga header berisi
+	// AuthScheme + " " + token, misal "Token" untuk gateway self-hosted.
+	// NewAIModelConnector mengisinya dengan DefaultAuthScheme; string kosong
+	// mengirim token saja, untuk gateway yang memakai API key. Karena itu
+	// connector yang dibuat tanpa NewAIModelConnector perlu mengisinya sendiri.
+	AuthScheme string
+	// Pool mengatur pool koneksi http.Client yang dibuat NewAIModelConnector.
+	// Diabaikan jika Client diberikan sendiri.
+	Pool ConnectionPool
+
+	// state berisi status yang berubah selama connector dipakai. Disimpan
+	// lewat pointer agar connector yang sudah dikonfigurasi bisa disalin
+	// (c2 := *c) tanpa menyalin lock; salinannya berbagi cache dan status
+	// Retry-After dengan aslinya.
+	state *connectorState
+}
+
+// connectorState adalah status bersama milik AIModelConnector. mu melindungi
+// notBefore, yaitu waktu paling awal permintaan berikutnya boleh dikirim
+// setelah API membalas 429 dengan Retry-After, dan cache yang dibuat saat
+// pertama kali dibutuhkan.
+type connectorState struct {
+	mu        sync.Mutex
+	notBefore time.Time
+	cache     *responseCache
+}
+
+// stateMu melindungi pembuatan state untuk connector yang dibuat tanpa
+// NewAIModelConnector, misal &AIModelConnector{Client: client}.
+var stateMu sync.Mutex
+
+// shared mengembalikan state milik c, membuatnya saat pertama kali dibutuhkan.
+func (c *AIModelConnector) shared() *connectorState {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if c.state == nil {
+		c.state = &connectorState{}
+	}
+	return c.state
+}
+
+// DefaultTimeout adalah timeout http.Client yang dibuat oleh NewAIModelConnector
+// ketika tidak ada client yang diberikan.
+const DefaultTimeout = 30 * time.Second
+
+// Option mengatur satu field AIModelConnector saat dibuat dengan NewAIModelConnector.
+type Option func(*AIModelConnector)
+
+// WithHTTPClient memakai client yang diberikan alih-alih client default.
+func WithHTTPClient(client HTTPDoer) Option {
+	return func(c *AIModelConnector) {
+		c.Client = client
+	}
+}
+
+// WithModel mengatur ID model yang dipanggil.
+func WithModel(model string) Option {
+	return func(c *AIModelConnector) {
+		c.Model = model
+	}
+}
+
+// WithBaseURL mengatur alamat inference API.
+func WithBaseURL(baseURL string) Option {
+	return func(c *AIModelConnector) {
+		c.BaseURL = baseURL
+	}
+}
+
+// WithRetries mengatur jumlah pengulangan dan batas lama tunggu ketika model
+// masih dimuat atau jaringan sedang terganggu.
+func WithRetries(maxRetries int, maxWait time.Duration) Option {
+	return func(c *AIModelConnector) {
+		c.MaxRetries = maxRetries
+		c.MaxWait = maxWait
+	}
+}
+
+// WithMaxRows membatasi jumlah baris tabel yang dikirim ke model.
+func WithMaxRows(maxRows int) Option {
+	return func(c *AIModelConnector) {
+		c.MaxRows = maxRows
+	}
+}
+
+// WithMaxColumns membatasi jumlah kolom tabel yang boleh dikirim ke model.
+func WithMaxColumns(maxColumns int) Option {
+	return func(c *AIModelConnector) {
+		c.MaxColumns = maxColumns
+	}
+}
+
+// WithMaxPayloadBytes membatasi ukuran body JSON yang dikirim ke model.
+func WithMaxPayloadBytes(maxBytes int) Option {
+	return func(c *AIModelConnector) {
+		c.MaxPayloadBytes = maxBytes
+	}
+}
+
+// WithLogger mengaktifkan log verbose ke logger yang diberikan.
+func WithLogger(logger *log.Logger) Option {
+	return func(c *AIModelConnector) {
+		c.Logger = logger
+	}
+}
+
+// WithDryRun membuat connector hanya mencatat permintaan tanpa mengirimnya.
+func WithDryRun() Option {
+	return func(c *AIModelConnector) {
+		c.DryRun = true
+	}
+}
+
+// WithUserAgent mengatur header User-Agent setiap permintaan.
+func WithUserAgent(userAgent string) Option {
+	return func(c *AIModelConnector) {
+		c.UserAgent = userAgent
+	}
+}
+
+// WithAuthScheme mengatur skema header Authorization, lihat
+// AIModelConnector.AuthScheme.
+func WithAuthScheme(scheme string) Option {
+	return func(c *AIModelConnector) {
+		c.AuthScheme = scheme
+	}
+}
+
+// WithConnectionPool mengatur pool koneksi http.Client yang dibuat
+// NewAIModelConnector, misal agar batch dengan Concurrency tinggi memakai
+// ulang koneksi ke host yang sama.
+func WithConnectionPool(pool ConnectionPool) Option {
+	return func(c *AIModelConnector) {
+		c.Pool = pool
+	}
+}
+
+// NewAIModelConnector membuat AIModelConnector dengan opsi yang diberikan.
+// Jika tidak ada client yang diberikan, dibuat http.Client dengan
+// DefaultTimeout dan pool koneksi sesuai Pool.
+func NewAIModelConnector(opts ...Option) *AIModelConnector {
+	c := &AIModelConnector{AuthScheme: DefaultAuthScheme, state: &connectorState{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.Client == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		SetConnectionPool(t, c.Pool)
+		c.Client = &http.Client{Timeout: DefaultTimeout, Transport: t}
+	}
+	return c
+}
+
+// WithPayloadFormat mengatur bentuk tabel di body permintaan, misal
+// PayloadRecords untuk endpoint yang mengharapkan baris.
+func WithPayloadFormat(format PayloadFormat) Option {
+	return func(c *AIModelConnector) {
+		c.PayloadFormat = format
+	}
+}
+
+// WithColumnOrder mengirim kolom tabel dengan urutan columns, misal
+// Table.Columns agar kolom dari AddRowNumbers benar-benar menjadi kolom
+// pertama yang dibaca model.
+func WithColumnOrder(columns []string) Option {
+	return func(c *AIModelConnector) {
+		c.ColumnOrder = columns
+	}
+}
+
+// WithRevision mengunci versi model yang dipakai, lihat AIModelConnector.Revision.
+func WithRevision(revision string) Option {
+	return func(c *AIModelConnector) {
+		c.Revision = revision
+	}
+}
+
+// ErrInvalidRevision dikembalikan oleh ValidateRevision.
+var ErrInvalidRevision = errors.New("invalid model revision")
+
+// ValidateRevision memastikan revision berupa satu token yang tidak kosong
+// dan tanpa spasi atau karakter kontrol, misal "main", "v1.0" atau hash commit.
+func ValidateRevision(revision string) error {
+	if revision == "" {
+		return fmt.Errorf("%w: revision is empty", ErrInvalidRevision)
+	}
+	for _, r := range revision {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return fmt.Errorf("%w: %q contains whitespace or control characters", ErrInvalidRevision, revision)
+		}
+	}
+	return nil
+}
+
+// ModelURL mengembalikan URL inference yang dipanggil ConnectAIModel, disusun
+// dari BaseURL dan Model dengan nilai default untuk field yang kosong,
+// ditambah ?revision= jika Revision diisi.
+func (c *AIModelConnector) ModelURL() string {
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	u := baseURL + "/models/" + c.modelID()
+	if c.Revision != "" {
+		u += "?revision=" + url.QueryEscape(c.Revision)
+	}
+	return u
+}
+
+// modelID mengembalikan Model, atau DefaultModel jika kosong.
+func (c *AIModelConnector) modelID() string {
+	if c.Model == "" {
+		return DefaultModel
+	}
+	return c.Model
+}
+
+// Metrics mencatat detail satu panggilan ConnectAIModelWithMetrics.
+type Metrics struct {
+	// Duration adalah waktu sejak permintaan pertama dikirim sampai respons
+	// terakhir selesai dibaca, termasuk jeda di antara retry
+	Duration time.Duration
+	// StatusCode adalah status HTTP respons terakhir, atau nol jika tidak
+	// ada respons (misal input tidak valid atau jawaban diambil dari cache)
+	StatusCode int
+	// Attempts adalah jumlah permintaan HTTP yang dikirim
+	Attempts int
+	// Cached bernilai true jika jawaban diambil dari cache tanpa memanggil API
+	Cached bool
+	// TruncatedRows adalah jumlah baris yang dibuang karena MaxRows, agar
+	// pemanggil bisa memperingatkan pengguna tanpa Logger. Nol berarti
+	// seluruh tabel dikirim.
+	TruncatedRows int
+}
+
+// ConnectAIModel mengirim inputs ke model dan mengembalikan jawaban model.
+// Inputs divalidasi lebih dulu; status selain 200 dikembalikan sebagai *APIError.
+func (c *AIModelConnector) ConnectAIModel(ctx context.Context, inputs Inputs, token string) (Response, error) {
+	result, _, err := c.ConnectAIModelWithMetrics(ctx, inputs, token)
+	return result, err
+}
+
+// ConnectAIModelWithMetrics sama dengan ConnectAIModel, tetapi juga
+// mengembalikan Metrics berisi lama panggilan dan status HTTP-nya. Metrics
+// tetap diisi ketika API membalas dengan error. Permintaan yang melewati
+// batas waktu ctx atau timeout client dikembalikan sebagai *TimeoutError.
+func (c *AIModelConnector) ConnectAIModelWithMetrics(ctx context.Context, inputs Inputs, token string) (Response, Metrics, error) {
+	start := time.Now()
+	result, metrics, err := c.connect(ctx, inputs, token)
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = &TimeoutError{After: time.Since(start).Round(100 * time.Millisecond), Err: err}
+	}
+	return result, metrics, err
+}
+
+// connect berisi isi ConnectAIModelWithMetrics sebelum error timeout dibungkus.
+func (c *AIModelConnector) connect(ctx context.Context, inputs Inputs, token string) (Response, Metrics, error) {
+	var metrics Metrics
+
+	// Tolak input yang tidak valid sebelum mengirim permintaan apa pun
+	if err := inputs.Validate(); err != nil {
+		return Response{}, metrics, err
+	}
+
+	if c.Revision != "" {
+		if err := ValidateRevision(c.Revision); err != nil {
+			return Response{}, metrics, err
+		}
+	}
+
+	// Tabel yang terlalu lebar tidak bisa diperbaiki dengan memotong baris
+	if limit := c.maxColumns(); limit > 0 && len(inputs.Table) > limit {
+		return Response{}, metrics, &TooManyColumnsError{Columns: len(inputs.Table), Limit: limit}
+	}
+
+	// Potong tabel yang terlalu panjang agar tidak melebihi batas input model
+	if table, truncated := TruncateRows(inputs.Table, c.MaxRows); truncated {
+		c.logf("warning: table truncated to the first %d rows", c.MaxRows)
+		metrics.TruncatedRows = rowCount(Table{Columns: sortedKeys(inputs.Table), Data: inputs.Table}) - c.MaxRows
+		inputs.Table = table
+	}
+
+	// Serialize inputs menjadi JSON sesuai PayloadFormat
+	reqBody, err := marshalInputs(inputs, c.PayloadFormat, c.ColumnOrder)
+	if err != nil {
+		// Jika terjadi error saat serialisasi, kembalikan error
+		return Response{}, metrics, fmt.Errorf("encoding request: %w", err)
+	}
+
+	// Tolak body yang terlalu besar sebelum dikirim, karena server hanya
+	// membalas dengan error yang sulit dipahami
+	if limit := c.maxPayloadBytes(); limit > 0 && len(reqBody) > limit {
+		return Response{}, metrics, &PayloadTooLargeError{Size: len(reqBody), Limit: limit}
+	}
+
+	// Pada dry run, tampilkan permintaan yang akan dikirim tanpa mengirimnya
+	if c.DryRun {
+		c.logf("dry run: POST %s\n%s", c.ModelURL(), reqBody)
+		return Response{Answer: DryRunAnswer}, metrics, nil
+	}
+
+	// Pada mode replay, jawaban diambil dari rekaman tanpa jaringan
+	if c.ReplayDir != "" {
+		c.logf("replaying answer from %s", c.ReplayDir)
+		result, err := replay(c.ReplayDir, c.fixtureModel(), inputs.Query)
+		return result, metrics, err
+	}
+
+	// Pertanyaan yang sama untuk tabel dan model yang sama dijawab dari cache
+	cache := c.responseCache()
+	var key [sha256.Size]byte
+	if cache != nil {
+		key = c.cacheKey(reqBody)
+		if cached, ok := cache.get(key); ok {
+			c.logf("cache hit for %s", c.ModelURL())
+			metrics.Cached = true
+			return cached, metrics, nil
+		}
+	}
+
+	c.logf("POST %s (%d bytes)", c.ModelURL(), len(reqBody))
+
+	// Kompres body sekali saja, karena body yang sama dipakai ulang saat retry
+	body, encoding, err := c.encodeBody(reqBody)
+	if err != nil {
+		return Response{}, metrics, err
+	}
+
+	// Kirim permintaan, ulangi selama model masih dimuat (status 503) atau
+	// jaringan sedang terganggu, dan jatah retry belum habis
+	var resp *http.Response
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		metrics.Attempts++
+		resp, err = c.send(ctx, body, encoding, token)
+		if err != nil {
+			if !isRetryable(err) || attempt >= c.MaxRetries {
+				// Error permanen atau jatah retry habis, kembalikan error
+				metrics.Duration = time.Since(start)
+				return Response{}, metrics, err
+			}
+			wait := c.retryBackoff(attempt)
+			c.logf("network error, retrying in %v: %v", wait, err)
+			if err := sleepContext(ctx, wait); err != nil {
+				metrics.Duration = time.Since(start)
+				return Response{}, metrics, fmt.Errorf("waiting to retry: %w", err)
+			}
+			continue
+		}
+		if resp.StatusCode != http.StatusServiceUnavailable || attempt >= c.MaxRetries {
+			break
+		}
+
+		// Model sedang dimuat: tunggu sesuai estimated_time lalu coba lagi
+		wait := c.loadingWait(resp.Body)
+		resp.Body.Close()
+		if err := sleepContext(ctx, wait); err != nil {
+			metrics.Duration = time.Since(start)
+			metrics.StatusCode = resp.StatusCode
+			return Response{}, metrics, fmt.Errorf("waiting for model to load: %w", err)
+		}
+	}
+	// Pastikan untuk menutup body respons setelah selesai
+	defer resp.Body.Close()
+	metrics.StatusCode = resp.StatusCode
+
+	// Periksa status kode respons, jika tidak OK, kembalikan error
+	// beserta isi body agar pesan dari Hugging Face ikut terlihat
+	if resp.StatusCode != http.StatusOK {
+		// Samarkan token jika server memantulkannya di pesan error
+		body := readErrorBody(resp.Body, token)
+		metrics.Duration = time.Since(start)
+		return Response{}, metrics, &APIError{StatusCode: resp.StatusCode, Body: body}
+	}
+
+	// Baca seluruh body agar bisa disertakan di SchemaError jika bentuknya
+	// bukan jawaban TAPAS
+	respBody, err := io.ReadAll(resp.Body)
+	metrics.Duration = time.Since(start)
+	if err != nil {
+		return Response{}, metrics, fmt.Errorf("reading response: %w", err)
+	}
+
+	// Decode body respons JSON ke dalam struct Response
+	result, err := c.decodeResponse(respBody)
+	if err != nil {
+		// Jika terjadi error saat decoding, kembalikan error
+		return Response{}, metrics, fmt.Errorf("decoding response: %w", err)
+	}
+	c.logf("answered in %s (status %d, %d attempts)", metrics.Duration.Round(time.Millisecond), metrics.StatusCode, metrics.Attempts)
+
+	// Rekam jawaban agar bisa diputar ulang tanpa jaringan
+	if c.RecordDir != "" {
+		if err := record(c.RecordDir, c.fixtureModel(), inputs.Query, result); err != nil {
+			return Response{}, metrics, fmt.Errorf("recording response: %w", err)
+		}
+	}
+
+	// Simpan hanya jawaban yang berhasil agar error tidak ikut di-cache
+	if cache != nil {
+		cache.put(key, result)
+	}
+
+	// Kembalikan hasil decoding sebagai Response dan nil untuk error
+	return result, metrics, nil
+}
+
+// decodeResponse mengubah body JSON menjadi Response. Model lain (misal
+// summarization atau classification) membalas dengan JSON yang valid tetapi
+// berbentuk lain, sehingga semua field Response kosong; kasus ini dilaporkan
+// sebagai *SchemaError alih-alih jawaban kosong. Body yang bukan JSON sama
+// sekali dilaporkan sebagai *NonJSONError.
+func (c *AIModelConnector) decodeResponse(body []byte) (Response, error) {
+	var result Response
+	if err := json.Unmarshal(body, &result); err != nil {
+		// JSON valid dengan tipe lain, misal array [{"summary_text": ...}]
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return Response{}, &SchemaError{Model: c.modelID(), Body: string(body), Err: err}
+		}
+		// Body bukan JSON, misal halaman HTML dari proxy
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			snippet := body
+			if len(snippet) > maxSnippetLen {
+				snippet = snippet[:maxSnippetLen]
+			}
+			return Response{}, &NonJSONError{Body: string(snippet), Err: err}
+		}
+		return Response{}, err
+	}
+	if result.Answer == "" && result.Aggregator == "" && len(result.Cells) == 0 && len(result.Coordinates) == 0 {
+		return Response{}, &SchemaError{Model: c.modelID(), Body: string(body)}
+	}
+	return result, nil
+}
+
+// maxColumns mengembalikan MaxColumns, atau DefaultMaxColumns jika nol.
+func (c *AIModelConnector) maxColumns() int {
+	if c.MaxColumns == 0 {
+		return DefaultMaxColumns
+	}
+	return c.MaxColumns
+}
+
+// maxPayloadBytes mengembalikan MaxPayloadBytes, atau DefaultMaxPayloadBytes jika nol.
+func (c *AIModelConnector) maxPayloadBytes() int {
+	if c.MaxPayloadBytes == 0 {
+		return DefaultMaxPayloadBytes
+	}
+	return c.MaxPayloadBytes
+}
+
+// userAgent mengembalikan UserAgent, atau DefaultUserAgent jika kosong.
+func (c *AIModelConnector) userAgent() string {
+	if c.UserAgent == "" {
+		return DefaultUserAgent
+	}
+	return c.UserAgent
+}
+
+// authorization mengembalikan nilai header Authorization untuk token.
+func (c *AIModelConnector) authorization(token string) string {
+	if c.AuthScheme == "" {
+		return token
+	}
+	return c.AuthScheme + " " + token
+}
+
+// logf menulis log ke Logger jika diset.
+func (c *AIModelConnector) logf(format string, args ...interface{}) {
+	if c.Logger != nil {
+		c.Logger.Printf(format, args...)
+	}
+}
+
+// send membuat dan mengirim satu permintaan HTTP POST ke URL model. Jika
+// encoding tidak kosong, nilainya dikirim sebagai header Content-Encoding.
+func (c *AIModelConnector) send(ctx context.Context, reqBody []byte, encoding, token string) (*http.Response, error) {
+	// Hormati batas laju sebelum mengirim permintaan
+	if err := c.waitTurn(ctx); err != nil {
+		return nil, err
+	}
+
+	// Buat permintaan HTTP POST ke URL API yang terikat pada context,
+	// sehingga permintaan dibatalkan ketika context selesai atau timeout
+	req, err := http.NewRequestWithContext(ctx, "POST", c.ModelURL(), bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+
+	// Set header Authorization dengan token yang diberikan
+	req.Header.Set("Authorization", c.authorization(token))
+	// Set header Content-Type sebagai application/json
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", c.userAgent())
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	// Minta respons dalam gzip. Karena header ini diset sendiri, http.Transport
+	// tidak lagi mendekompresi otomatis sehingga decodeBody yang melakukannya.
+	if c.Compress {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+	// Token tidak boleh muncul di log, jadi header selalu disamarkan dulu
+	c.logf("request headers: %v", RedactHeader(req.Header))
+
+	// Kirim permintaan HTTP menggunakan client, atau http.DefaultClient
+	// jika connector dibuat tanpa client
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeBody(resp); err != nil {
+		return nil, err
+	}
+
+	// Terkena rate limit: tahan permintaan berikutnya sesuai Retry-After
+	if resp.StatusCode == http.StatusTooManyRequests {
+		c.noteRetryAfter(resp)
+	}
+	return resp, nil
+}
+
+// loadingWait membaca field estimated_time dari body respons 503
+// ("model is currently loading") dan membatasinya dengan MaxWait.
+func (c *AIModelConnector) loadingWait(body io.Reader) time.Duration {
+	maxWait := c.maxWait()
+
+	var loading struct {
+		EstimatedTime float64 `json:"estimated_time"`
+	}
+	wait := time.Second
+	if err := json.NewDecoder(body).Decode(&loading); err == nil && loading.EstimatedTime > 0 {
+		wait = time.Duration(loading.EstimatedTime * float64(time.Second))
+	}
+
+	if wait > maxWait {
+		return maxWait
+	}
+	return wait
+}
+
+// maxWait mengembalikan MaxWait, atau DefaultMaxWait jika tidak diisi.
+func (c *AIModelConnector) maxWait() time.Duration {
+	if c.MaxWait <= 0 {
+		return DefaultMaxWait
+	}
+	return c.MaxWait
+}
+
+// sleepContext menunggu selama d, atau berhenti lebih awal dengan error
+// ketika context dibatalkan.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
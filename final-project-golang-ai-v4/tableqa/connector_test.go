@@ -0,0 +1,743 @@
+package tableqa_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type MockClient struct {
+	MockRoundTrip func(req *http.Request) (*http.Response, error)
+}
+
+func (m *MockClient) RoundTrip(req *http.Request) (*http.Response, error) {
+	return m.MockRoundTrip(req)
+}
+
+// DoerFunc adalah HTTPDoer tiruan yang memanggil fungsi biasa.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+var _ = Describe("AIModelConnector", func() {
+	Describe("newAIModelConnector", func() {
+		It("creates a client with the default timeout when none is given", func() {
+			connector := tableqa.NewAIModelConnector()
+			Expect(connector.Client).ShouldNot(BeNil())
+			Expect(connector.Client).Should(BeAssignableToTypeOf(&http.Client{}))
+			Expect(connector.Client.(*http.Client).Timeout).Should(Equal(tableqa.DefaultTimeout))
+			Expect(connector.Model).Should(BeEmpty())
+		})
+
+		It("applies the given options", func() {
+			client := &http.Client{Timeout: time.Minute}
+			connector := tableqa.NewAIModelConnector(
+				tableqa.WithHTTPClient(client),
+				tableqa.WithModel("my-org/my-tapas"),
+				tableqa.WithBaseURL("http://localhost:8080"),
+				tableqa.WithRetries(3, time.Second),
+			)
+			Expect(connector.Client).Should(BeIdenticalTo(client))
+			Expect(connector.Model).Should(Equal("my-org/my-tapas"))
+			Expect(connector.BaseURL).Should(Equal("http://localhost:8080"))
+			Expect(connector.MaxRetries).Should(Equal(3))
+			Expect(connector.MaxWait).Should(Equal(time.Second))
+		})
+	})
+
+	Describe("connectAIModel", func() {
+		It("sends the inputs and token and decodes the response using a stub doer", func() {
+			var gotBody tableqa.Inputs
+			var gotAuth, gotContentType, gotMethod string
+			doer := DoerFunc(func(req *http.Request) (*http.Response, error) {
+				gotMethod = req.Method
+				gotAuth = req.Header.Get("Authorization")
+				gotContentType = req.Header.Get("Content-Type")
+				Expect(json.NewDecoder(req.Body).Decode(&gotBody)).Should(Succeed())
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader(`{"answer": "30", "coordinates": [[0, 1]], "cells": ["30"], "aggregator": "NONE"}`)),
+				}, nil
+			})
+
+			payload := tableqa.Inputs{
+				Table: map[string][]string{"Name": {"John", "Doe"}, "Age": {"30", "40"}},
+				Query: "What is the age of John?",
+			}
+
+			connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(doer))
+			result, err := connector.ConnectAIModel(context.Background(), payload, "secret-token")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(gotMethod).Should(Equal(http.MethodPost))
+			Expect(gotAuth).Should(Equal("Bearer secret-token"))
+			Expect(gotContentType).Should(Equal("application/json"))
+			Expect(gotBody).Should(Equal(payload))
+			Expect(result).Should(Equal(tableqa.Response{
+				Answer:      "30",
+				Coordinates: [][]int{{0, 1}},
+				Cells:       []string{"30"},
+				Aggregator:  "NONE",
+			}))
+		})
+
+		DescribeTable("sends the token with the auth scheme",
+			func(opts []tableqa.Option, expected string) {
+				var gotAuth string
+				doer := DoerFunc(func(req *http.Request) (*http.Response, error) {
+					gotAuth = req.Header.Get("Authorization")
+					return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{"answer": "30"}`))}, nil
+				})
+
+				connector := tableqa.NewAIModelConnector(append(opts, tableqa.WithHTTPClient(doer))...)
+				_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{
+					Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+					Query: "What is the age of John?",
+				}, "secret-token")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(gotAuth).Should(Equal(expected))
+			},
+			Entry("default", nil, "Bearer secret-token"),
+			Entry("custom scheme", []tableqa.Option{tableqa.WithAuthScheme("Token")}, "Token secret-token"),
+			Entry("empty scheme sends the raw token", []tableqa.Option{tableqa.WithAuthScheme("")}, "secret-token"),
+		)
+
+		It("works against an httptest server", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Path).Should(Equal("/models/" + tableqa.DefaultModel))
+				Expect(r.Header.Get("Authorization")).Should(Equal("Bearer secret-token"))
+				w.Write([]byte(`{"answer": "Doe", "coordinates": [[1, 0]], "cells": ["Doe"], "aggregator": "NONE"}`))
+			}))
+			defer server.Close()
+
+			connector := tableqa.NewAIModelConnector(
+				tableqa.WithHTTPClient(server.Client()),
+				tableqa.WithBaseURL(server.URL),
+			)
+			result, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{
+				Table: map[string][]string{"Name": {"John", "Doe"}, "Age": {"30", "40"}},
+				Query: "Who is 40 years old?",
+			}, "secret-token")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.Answer).Should(Equal("Doe"))
+			Expect(result.Coordinates).Should(Equal([][]int{{1, 0}}))
+		})
+
+		It("sends the request to BaseURL + /models/ + Model", func() {
+			var requestedURL string
+			mockClient := &MockClient{
+				MockRoundTrip: func(req *http.Request) (*http.Response, error) {
+					requestedURL = req.URL.String()
+					return &http.Response{
+						StatusCode: 200,
+						Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"answer": "30"}`))),
+					}, nil
+				},
+			}
+
+			payload := tableqa.Inputs{
+				Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+				Query: "What is the age of John?",
+			}
+
+			connector := &tableqa.AIModelConnector{
+				Client:  &http.Client{Transport: mockClient},
+				BaseURL: "http://localhost:8080",
+				Model:   "my-org/my-tapas",
+			}
+			_, err := connector.ConnectAIModel(context.Background(), payload, "token")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(requestedURL).Should(Equal("http://localhost:8080/models/my-org/my-tapas"))
+
+			connector = &tableqa.AIModelConnector{
+				Client: &http.Client{Transport: mockClient},
+			}
+			_, err = connector.ConnectAIModel(context.Background(), payload, "token")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(requestedURL).Should(Equal(tableqa.DefaultBaseURL + "/models/" + tableqa.DefaultModel))
+		})
+
+		DescribeTable("sets the User-Agent header",
+			func(opts []tableqa.Option, want string) {
+				var gotUserAgent string
+				doer := DoerFunc(func(req *http.Request) (*http.Response, error) {
+					gotUserAgent = req.Header.Get("User-Agent")
+					return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{"answer": "30"}`))}, nil
+				})
+
+				connector := tableqa.NewAIModelConnector(append(opts, tableqa.WithHTTPClient(doer))...)
+				_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{
+					Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+					Query: "What is the age of John?",
+				}, "token")
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(gotUserAgent).Should(Equal(want))
+			},
+			Entry("default", nil, tableqa.DefaultUserAgent),
+			Entry("custom", []tableqa.Option{tableqa.WithUserAgent("my-app/2.0")}, "my-app/2.0"),
+		)
+
+		It("pins the model revision in the request URL when set", func() {
+			var requested string
+			connector := tableqa.NewAIModelConnector(
+				tableqa.WithRevision("v1.0/main"),
+				tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+					requested = req.URL.String()
+					return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{"answer": "30"}`))}, nil
+				})),
+			)
+
+			_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{
+				Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+				Query: "What is the age of John?",
+			}, "token")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(requested).Should(Equal(tableqa.DefaultBaseURL + "/models/" + tableqa.DefaultModel + "?revision=v1.0%2Fmain"))
+			Expect(connector.ModelURL()).Should(Equal(requested))
+		})
+
+		It("rejects an invalid revision before sending", func() {
+			connector := tableqa.NewAIModelConnector(
+				tableqa.WithRevision("v1 .0"),
+				tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+					Fail("the request should not be sent")
+					return nil, nil
+				})),
+			)
+
+			_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{
+				Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+				Query: "What is the age of John?",
+			}, "token")
+			Expect(err).Should(MatchError(tableqa.ErrInvalidRevision))
+		})
+
+		DescribeTable("validates revisions",
+			func(revision string, valid bool) {
+				err := tableqa.ValidateRevision(revision)
+				if valid {
+					Expect(err).ShouldNot(HaveOccurred())
+				} else {
+					Expect(err).Should(MatchError(tableqa.ErrInvalidRevision))
+				}
+			},
+			Entry("a branch", "main", true),
+			Entry("a commit hash", "0a3d8c2f1e4b5a6978c0d1e2f3a4b5c6d7e8f901", true),
+			Entry("an empty revision", "", false),
+			Entry("whitespace", "  ", false),
+			Entry("a newline", "main\n", false),
+		)
+
+		It("returns a deadline error when the context expires before the model responds", func() {
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-release
+			}))
+			defer server.Close()
+			defer close(release)
+
+			connector := &tableqa.AIModelConnector{
+				Client:  server.Client(),
+				BaseURL: server.URL,
+			}
+
+			payload := tableqa.Inputs{
+				Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+				Query: "What is the age of John?",
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			_, err := connector.ConnectAIModel(ctx, payload, "token")
+			Expect(err).Should(HaveOccurred())
+			Expect(errors.Is(err, context.DeadlineExceeded)).Should(BeTrue())
+			Expect(time.Since(start)).Should(BeNumerically("<", time.Second))
+
+			var timeoutErr *tableqa.TimeoutError
+			Expect(errors.As(err, &timeoutErr)).Should(BeTrue())
+			Expect(timeoutErr.After).Should(BeNumerically("~", 50*time.Millisecond, 100*time.Millisecond))
+		})
+
+		It("explains a context that expired before the request was sent", func() {
+			connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+				return nil, req.Context().Err()
+			})))
+
+			ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+			defer cancel()
+
+			_, err := connector.ConnectAIModel(ctx, tableqa.Inputs{
+				Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+				Query: "What is the age of John?",
+			}, "token")
+			Expect(err).Should(MatchError("request timed out after 0s; try increasing -timeout"))
+			Expect(errors.Is(err, context.DeadlineExceeded)).Should(BeTrue())
+		})
+
+		It("does not report a cancelled context as a timeout", func() {
+			connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+				return nil, req.Context().Err()
+			})))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := connector.ConnectAIModel(ctx, tableqa.Inputs{
+				Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+				Query: "What is the age of John?",
+			}, "token")
+			Expect(errors.Is(err, context.Canceled)).Should(BeTrue())
+			var timeoutErr *tableqa.TimeoutError
+			Expect(errors.As(err, &timeoutErr)).Should(BeFalse())
+		})
+
+		It("retries while the model is loading and returns the final response", func() {
+			attempts := 0
+			mockClient := &MockClient{
+				MockRoundTrip: func(req *http.Request) (*http.Response, error) {
+					attempts++
+					if attempts <= 2 {
+						return &http.Response{
+							StatusCode: http.StatusServiceUnavailable,
+							Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"error": "Model is currently loading", "estimated_time": 20.0}`))),
+						}, nil
+					}
+					return &http.Response{
+						StatusCode: 200,
+						Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"answer": "30", "coordinates": [[0, 1]], "cells": ["30"], "aggregator": "NONE"}`))),
+					}, nil
+				},
+			}
+
+			connector := &tableqa.AIModelConnector{
+				Client:     &http.Client{Transport: mockClient},
+				MaxRetries: 3,
+				MaxWait:    10 * time.Millisecond,
+			}
+
+			payload := tableqa.Inputs{
+				Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+				Query: "What is the age of John?",
+			}
+
+			result, err := connector.ConnectAIModel(context.Background(), payload, "token")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(attempts).Should(Equal(3))
+			Expect(result).Should(Equal(tableqa.Response{
+				Answer:      "30",
+				Coordinates: [][]int{{0, 1}},
+				Cells:       []string{"30"},
+				Aggregator:  "NONE",
+			}))
+		})
+
+		It("gives up once MaxRetries is exhausted", func() {
+			attempts := 0
+			mockClient := &MockClient{
+				MockRoundTrip: func(req *http.Request) (*http.Response, error) {
+					attempts++
+					return &http.Response{
+						StatusCode: http.StatusServiceUnavailable,
+						Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"estimated_time": 0.001}`))),
+					}, nil
+				},
+			}
+
+			connector := &tableqa.AIModelConnector{
+				Client:     &http.Client{Transport: mockClient},
+				MaxRetries: 2,
+			}
+
+			payload := tableqa.Inputs{
+				Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+				Query: "What is the age of John?",
+			}
+
+			_, err := connector.ConnectAIModel(context.Background(), payload, "token")
+			Expect(err).Should(HaveOccurred())
+			Expect(attempts).Should(Equal(3))
+		})
+
+		It("surfaces the response body in an APIError on non-200 status", func() {
+			mockClient := &MockClient{
+				MockRoundTrip: func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusBadRequest,
+						Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"error": "table must not be empty"}`))),
+					}, nil
+				},
+			}
+
+			connector := &tableqa.AIModelConnector{
+				Client: &http.Client{Transport: mockClient},
+			}
+
+			payload := tableqa.Inputs{
+				Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+				Query: "What is the age of John?",
+			}
+
+			_, err := connector.ConnectAIModel(context.Background(), payload, "token")
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).Should(ContainSubstring("table must not be empty"))
+
+			var apiErr *tableqa.APIError
+			Expect(errors.As(err, &apiErr)).Should(BeTrue())
+			Expect(apiErr.StatusCode).Should(Equal(http.StatusBadRequest))
+			Expect(apiErr.Body).Should(Equal(`{"error": "table must not be empty"}`))
+		})
+
+		DescribeTable("returns ErrUnauthorized when the token is rejected",
+			func(status int) {
+				connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: status,
+						Body:       ioutil.NopCloser(strings.NewReader(`{"error": "Invalid credentials in Authorization header"}`)),
+					}, nil
+				})))
+
+				_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{
+					Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+					Query: "What is the age of John?",
+				}, "hf_invalid")
+				Expect(errors.Is(err, tableqa.ErrUnauthorized)).Should(BeTrue())
+				Expect(err).Should(MatchError(ContainSubstring("check that HUGGINGFACE_TOKEN is set")))
+
+				var apiErr *tableqa.APIError
+				Expect(errors.As(err, &apiErr)).Should(BeTrue())
+				Expect(apiErr.StatusCode).Should(Equal(status))
+			},
+			Entry("401 Unauthorized", http.StatusUnauthorized),
+			Entry("403 Forbidden", http.StatusForbidden),
+		)
+
+		It("does not return ErrUnauthorized for other statuses", func() {
+			err := &tableqa.APIError{StatusCode: http.StatusBadRequest}
+			Expect(errors.Is(err, tableqa.ErrUnauthorized)).Should(BeFalse())
+			Expect(err.Error()).ShouldNot(ContainSubstring("HUGGINGFACE_TOKEN"))
+		})
+
+		It("logs the model URL and request size when a Logger is set", func() {
+			mockClient := &MockClient{
+				MockRoundTrip: func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: 200,
+						Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"answer": "30"}`))),
+					}, nil
+				},
+			}
+
+			var logs bytes.Buffer
+			connector := tableqa.NewAIModelConnector(
+				tableqa.WithHTTPClient(&http.Client{Transport: mockClient}),
+				tableqa.WithLogger(log.New(&logs, "", 0)),
+			)
+
+			payload := tableqa.Inputs{
+				Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+				Query: "What is the age of John?",
+			}
+
+			_, err := connector.ConnectAIModel(context.Background(), payload, "token")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(logs.String()).Should(ContainSubstring(tableqa.DefaultBaseURL + "/models/" + tableqa.DefaultModel))
+			Expect(logs.String()).Should(MatchRegexp(`\(\d+ bytes\)`))
+		})
+	})
+})
+
+var _ = Describe("connectAIModelWithMetrics", func() {
+	payload := tableqa.Inputs{
+		Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+		Query: "What is the age of John?",
+	}
+
+	It("measures the duration and status of the round-trip", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(10 * time.Millisecond)
+			w.Write([]byte(`{"answer": "30"}`))
+		}))
+		defer server.Close()
+
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(server.Client()), tableqa.WithBaseURL(server.URL))
+		result, metrics, err := connector.ConnectAIModelWithMetrics(context.Background(), payload, "token")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.Answer).Should(Equal("30"))
+		Expect(metrics.Duration).Should(BeNumerically(">=", 10*time.Millisecond))
+		Expect(metrics.StatusCode).Should(Equal(http.StatusOK))
+		Expect(metrics.Attempts).Should(Equal(1))
+		Expect(metrics.Cached).Should(BeFalse())
+	})
+
+	It("fills in metrics for an API error and counts retries", func() {
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithRetries(2, time.Millisecond),
+			tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Body:       ioutil.NopCloser(strings.NewReader(`{"estimated_time": 1}`)),
+				}, nil
+			})),
+		)
+
+		_, metrics, err := connector.ConnectAIModelWithMetrics(context.Background(), payload, "token")
+		Expect(err).Should(HaveOccurred())
+		Expect(metrics.StatusCode).Should(Equal(http.StatusServiceUnavailable))
+		Expect(metrics.Attempts).Should(Equal(3))
+		Expect(metrics.Duration).Should(BeNumerically(">", 0))
+	})
+
+	It("reports a cache hit without a status", func() {
+		calls := 0
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithCache(0),
+			tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{"answer": "30"}`))}, nil
+			})),
+		)
+
+		_, _, err := connector.ConnectAIModelWithMetrics(context.Background(), payload, "token")
+		Expect(err).ShouldNot(HaveOccurred())
+		_, metrics, err := connector.ConnectAIModelWithMetrics(context.Background(), payload, "token")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(calls).Should(Equal(1))
+		Expect(metrics).Should(Equal(tableqa.Metrics{Cached: true}))
+	})
+})
+
+var _ = Describe("dryRun", func() {
+	It("logs the request and returns a synthetic answer without calling the API", func() {
+		calls := 0
+		var logs bytes.Buffer
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithDryRun(),
+			tableqa.WithLogger(log.New(&logs, "", 0)),
+			tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				return nil, errors.New("unexpected request")
+			})),
+		)
+
+		result, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{
+			Table: map[string][]string{"Name": {"John"}},
+			Query: "Who is John?",
+		}, "secret-token")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(calls).Should(BeZero())
+		Expect(result).Should(Equal(tableqa.Response{Answer: tableqa.DryRunAnswer}))
+		Expect(logs.String()).Should(Equal("dry run: POST " + tableqa.DefaultBaseURL + "/models/" + tableqa.DefaultModel + "\n" +
+			`{"table":{"Name":["John"]},"query":"Who is John?"}` + "\n"))
+		Expect(logs.String()).ShouldNot(ContainSubstring("secret-token"))
+	})
+
+	It("still validates the inputs", func() {
+		connector := tableqa.NewAIModelConnector(tableqa.WithDryRun())
+		_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{Query: "Who is John?"}, "token")
+		Expect(err).Should(MatchError(tableqa.ErrEmptyTable))
+	})
+})
+
+var _ = Describe("responseSchema", func() {
+	connect := func(body string) (tableqa.Response, error) {
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithModel("facebook/bart-large-cnn"),
+			tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+			})),
+		)
+		return connector.ConnectAIModel(context.Background(), tableqa.Inputs{
+			Table: map[string][]string{"Name": {"John"}},
+			Query: "Who is John?",
+		}, "token")
+	}
+
+	DescribeTable("rejects JSON in another model's shape",
+		func(body string) {
+			_, err := connect(body)
+			Expect(err).Should(MatchError(ContainSubstring("response did not match expected schema for model facebook/bart-large-cnn")))
+
+			var schemaErr *tableqa.SchemaError
+			Expect(errors.As(err, &schemaErr)).Should(BeTrue())
+			Expect(schemaErr.Model).Should(Equal("facebook/bart-large-cnn"))
+			Expect(schemaErr.Body).Should(Equal(body))
+		},
+		Entry("summarization", `[{"summary_text": "John is a person."}]`),
+		Entry("classification", `[[{"label": "POSITIVE", "score": 0.99}]]`),
+		Entry("object with unrelated fields", `{"generated_text": "John"}`),
+		Entry("empty object", `{}`),
+	)
+
+	It("accepts coordinates encoded as strings", func() {
+		result, err := connect(`{"answer": "John", "coordinates": [["0", "0"]], "cells": ["John"], "aggregator": "NONE"}`)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.Coordinates).Should(Equal([][]int{{0, 0}}))
+	})
+
+	It("reports coordinates of the wrong type as a schema error", func() {
+		_, err := connect(`{"answer": "John", "coordinates": [["first", "0"]]}`)
+		var schemaErr *tableqa.SchemaError
+		Expect(errors.As(err, &schemaErr)).Should(BeTrue())
+	})
+
+	It("accepts a TAPAS answer with an empty answer text", func() {
+		result, err := connect(`{"answer": "", "coordinates": [], "cells": [], "aggregator": "NONE"}`)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.Aggregator).Should(Equal("NONE"))
+	})
+
+	It("keeps the decode error for fields of the wrong type", func() {
+		_, err := connect(`{"answer": "John", "cells": [1]}`)
+
+		var schemaErr *tableqa.SchemaError
+		Expect(errors.As(err, &schemaErr)).Should(BeTrue())
+		var typeErr *json.UnmarshalTypeError
+		Expect(errors.As(err, &typeErr)).Should(BeTrue())
+		Expect(typeErr.Field).Should(HavePrefix("cells"))
+	})
+
+	It("still reports malformed JSON as a decode error", func() {
+		_, err := connect(`{"answer": `)
+		Expect(err).Should(MatchError(HavePrefix("decoding response: ")))
+
+		var schemaErr *tableqa.SchemaError
+		Expect(errors.As(err, &schemaErr)).Should(BeFalse())
+		var syntaxErr *json.SyntaxError
+		Expect(errors.As(err, &syntaxErr)).Should(BeTrue())
+	})
+
+	It("reports an HTML error page as a non-JSON response", func() {
+		_, err := connect("<html><head><title>502 Bad Gateway</title></head><body>nginx</body></html>")
+		Expect(err).Should(MatchError(ContainSubstring("received non-JSON response")))
+		Expect(err).Should(MatchError(ContainSubstring("502 Bad Gateway")))
+
+		var nonJSON *tableqa.NonJSONError
+		Expect(errors.As(err, &nonJSON)).Should(BeTrue())
+		Expect(nonJSON.Body).Should(HavePrefix("<html>"))
+		var syntaxErr *json.SyntaxError
+		Expect(errors.As(err, &syntaxErr)).Should(BeTrue())
+	})
+
+	It("includes only the first 200 bytes of the body", func() {
+		_, err := connect("<html>" + strings.Repeat("x", 500) + "</html>")
+
+		var nonJSON *tableqa.NonJSONError
+		Expect(errors.As(err, &nonJSON)).Should(BeTrue())
+		Expect(nonJSON.Body).Should(HaveLen(200))
+		Expect(err.Error()).ShouldNot(ContainSubstring("</html>"))
+	})
+})
+
+var _ = Describe("maxPayloadBytes", func() {
+	payload := tableqa.Inputs{
+		Table: map[string][]string{"Name": {"John", "Jane", "Jim"}, "Age": {"30", "40", "50"}},
+		Query: "What is the age of John?",
+	}
+	body, _ := json.Marshal(payload)
+
+	It("rejects a payload over the limit without calling the API", func() {
+		calls := 0
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithMaxPayloadBytes(32),
+			tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				return nil, errors.New("unexpected request")
+			})),
+		)
+
+		_, err := connector.ConnectAIModel(context.Background(), payload, "token")
+		Expect(err).Should(MatchError(tableqa.ErrPayloadTooLarge))
+		Expect(calls).Should(BeZero())
+
+		var sizeErr *tableqa.PayloadTooLargeError
+		Expect(errors.As(err, &sizeErr)).Should(BeTrue())
+		Expect(*sizeErr).Should(Equal(tableqa.PayloadTooLargeError{Size: len(body), Limit: 32}))
+		Expect(err.Error()).Should(Equal(fmt.Sprintf("payload of %d bytes exceeds limit 32; reduce rows or columns", len(body))))
+	})
+
+	DescribeTable("sends payloads within the limit",
+		func(limit int) {
+			connector := tableqa.NewAIModelConnector(
+				tableqa.WithMaxPayloadBytes(limit),
+				tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{"answer": "30"}`))}, nil
+				})),
+			)
+			_, err := connector.ConnectAIModel(context.Background(), payload, "token")
+			Expect(err).ShouldNot(HaveOccurred())
+		},
+		Entry("default limit", 0),
+		Entry("no limit", -1),
+		Entry("exactly at the limit", len(body)),
+	)
+})
+
+var _ = Describe("maxColumns", func() {
+	wideTable := func(columns int) map[string][]string {
+		table := make(map[string][]string, columns)
+		for i := 0; i < columns; i++ {
+			table[fmt.Sprintf("Column%d", i)] = []string{"1"}
+		}
+		return table
+	}
+
+	It("rejects a table over the default limit without calling the API", func() {
+		calls := 0
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithMaxPayloadBytes(-1),
+			tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				return nil, errors.New("unexpected request")
+			})),
+		)
+
+		_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{
+			Table: wideTable(tableqa.DefaultMaxColumns + 1),
+			Query: "What is the total?",
+		}, "token")
+		Expect(err).Should(MatchError(tableqa.ErrTooManyColumns))
+		Expect(calls).Should(BeZero())
+
+		var columnsErr *tableqa.TooManyColumnsError
+		Expect(errors.As(err, &columnsErr)).Should(BeTrue())
+		Expect(*columnsErr).Should(Equal(tableqa.TooManyColumnsError{Columns: tableqa.DefaultMaxColumns + 1, Limit: tableqa.DefaultMaxColumns}))
+		Expect(err.Error()).Should(ContainSubstring("-columns"))
+	})
+
+	DescribeTable("sends tables within the limit",
+		func(limit, columns int) {
+			connector := tableqa.NewAIModelConnector(
+				tableqa.WithMaxColumns(limit),
+				tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{"answer": "1"}`))}, nil
+				})),
+			)
+			_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{Table: wideTable(columns), Query: "What is the total?"}, "token")
+			Expect(err).ShouldNot(HaveOccurred())
+		},
+		Entry("exactly at the limit", 3, 3),
+		Entry("no limit", -1, tableqa.DefaultMaxColumns+1),
+	)
+
+	It("applies a custom limit", func() {
+		connector := tableqa.NewAIModelConnector(tableqa.WithMaxColumns(2))
+		_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{Table: wideTable(3), Query: "What is the total?"}, "token")
+		Expect(err).Should(MatchError("table has 3 columns, more than the limit of 2; select a subset of columns (e.g. with -columns or SelectColumns)"))
+	})
+})
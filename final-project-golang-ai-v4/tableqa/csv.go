@@ -0,0 +1,364 @@
+package tableqa
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Table menyimpan data CSV per kolom beserta urutan kolom aslinya, karena
+// map tidak menjamin urutan saat diiterasi.
+type Table struct {
+	// Columns berisi nama kolom sesuai urutan header di CSV
+	Columns []string
+	// Data memetakan nama kolom ke nilai-nilai di kolom tersebut
+	Data map[string][]string
+}
+
+// Dimensions mengembalikan jumlah baris (kolom terpanjang) dan jumlah kolom t.
+func (t Table) Dimensions() (rows, cols int) {
+	return rowCount(t), len(t.Columns)
+}
+
+// Rows mengembalikan data t per baris, dengan nilai di setiap baris mengikuti
+// urutan Columns. Kolom yang lebih pendek dari kolom lain diisi string
+// kosong, sehingga setiap baris selalu sepanjang Columns.
+func (t Table) Rows() [][]string {
+	rows := make([][]string, rowCount(t))
+	for i := range rows {
+		rows[i] = t.row(i)
+	}
+	return rows
+}
+
+// RowMaps sama dengan Rows, tetapi setiap baris berupa map nama kolom ke
+// nilainya, misal untuk diubah menjadi JSON.
+func (t Table) RowMaps() []map[string]string {
+	rows := make([]map[string]string, rowCount(t))
+	for i := range rows {
+		row := make(map[string]string, len(t.Columns))
+		for j, value := range t.row(i) {
+			row[t.Columns[j]] = value
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+// row mengembalikan baris ke-i t, diisi string kosong untuk kolom yang lebih pendek.
+func (t Table) row(i int) []string {
+	record := make([]string, len(t.Columns))
+	for j, column := range t.Columns {
+		if values := t.Data[column]; i < len(values) {
+			record[j] = values[i]
+		}
+	}
+	return record
+}
+
+// CsvToSlice mengonversi string CSV menjadi map dengan key berupa header
+// kolom dan value berupa data setiap kolom. Baris pertama dianggap header.
+func CsvToSlice(data string) (map[string][]string, error) {
+	return CsvToSliceWithDelimiter(data, ',')
+}
+
+// CsvToSliceWithDelimiter sama dengan CsvToSlice, tetapi memakai delim sebagai
+// pemisah kolom, misal ';' atau '\t'. Delimiter yang tidak valid untuk
+// encoding/csv (misal '"', '\n', atau utf8.RuneError) dikembalikan sebagai error.
+func CsvToSliceWithDelimiter(data string, delim rune) (map[string][]string, error) {
+	table, err := CsvToTableWithDelimiter(data, delim)
+	if err != nil {
+		return nil, err
+	}
+	return table.Data, nil
+}
+
+// CsvToTable mengonversi string CSV menjadi Table yang mempertahankan urutan kolom.
+func CsvToTable(data string) (Table, error) {
+	return CsvToTableWithDelimiter(data, ',')
+}
+
+// CsvToTableWithDelimiter sama dengan CsvToTable, tetapi memakai delim sebagai
+// pemisah kolom.
+func CsvToTableWithDelimiter(data string, delim rune) (Table, error) {
+	return readTable(strings.NewReader(data), CSVOptions{Delimiter: delim})
+}
+
+// CsvReaderToSlice sama dengan CsvToSlice, tetapi membaca CSV langsung dari r
+// baris demi baris, sehingga file besar tidak perlu dimuat dulu ke dalam string.
+func CsvReaderToSlice(r io.Reader) (map[string][]string, error) {
+	table, err := readTable(r, CSVOptions{Delimiter: ','})
+	if err != nil {
+		return nil, err
+	}
+	return table.Data, nil
+}
+
+// CsvReaderToTable sama dengan CsvToTable, tetapi membaca CSV langsung dari r.
+func CsvReaderToTable(r io.Reader) (Table, error) {
+	return readTable(r, CSVOptions{Delimiter: ','})
+}
+
+// CsvReaderToTableWithDelimiter sama dengan CsvReaderToTable, tetapi memakai
+// delim sebagai pemisah kolom.
+func CsvReaderToTableWithDelimiter(r io.Reader, delim rune) (Table, error) {
+	return readTable(r, CSVOptions{Delimiter: delim})
+}
+
+// ErrNoData dikembalikan oleh CheckTableData ketika CSV tidak berisi header
+// atau tidak berisi satu baris data pun.
+var ErrNoData = errors.New("CSV contains no data")
+
+// CheckTableData memastikan t memiliki setidaknya satu kolom dan satu baris
+// data. CSV kosong terbaca tanpa error, sehingga periksa hasilnya dengan
+// fungsi ini sebelum tabel dikirim ke API.
+func CheckTableData(t Table) error {
+	if len(t.Columns) == 0 || rowCount(t) == 0 {
+		return ErrNoData
+	}
+	return nil
+}
+
+// ErrDuplicateHeader dikembalikan ketika header CSV berisi nama kolom yang
+// sama lebih dari sekali dan CSVOptions.Duplicates bernilai RejectDuplicates.
+var ErrDuplicateHeader = errors.New("duplicate column header")
+
+// DuplicateHeaders menentukan cara ReadTable menangani nama kolom yang sama.
+type DuplicateHeaders int
+
+const (
+	// RenameDuplicates (default) memberi akhiran _2, _3, dan seterusnya pada
+	// kolom kedua dan berikutnya dengan nama yang sama, sehingga tidak ada
+	// data yang hilang, misal "Name", "Name" menjadi "Name", "Name_2".
+	RenameDuplicates DuplicateHeaders = iota
+	// RejectDuplicates mengembalikan error yang membungkus ErrDuplicateHeader.
+	RejectDuplicates
+)
+
+// CSVOptions mengatur cara ReadTable membaca CSV. Nilai nol berarti CSV
+// dengan pemisah koma dan header duplikat diberi akhiran.
+type CSVOptions struct {
+	// Delimiter adalah pemisah kolom. Jika nol, ',' yang digunakan.
+	Delimiter rune
+	// Duplicates menentukan penanganan nama kolom yang sama
+	Duplicates DuplicateHeaders
+	// Encoding adalah encoding karakter r. Jika kosong, EncodingUTF8.
+	Encoding Encoding
+}
+
+// ReadTable membaca CSV dari r menjadi Table sesuai opts. Fungsi CsvTo* dan
+// CsvReaderTo* lainnya memakai ReadTable dengan opsi default.
+func ReadTable(r io.Reader, opts CSVOptions) (Table, error) {
+	if opts.Delimiter == 0 {
+		opts.Delimiter = ','
+	}
+	r, err := DecodeReader(r, opts.Encoding)
+	if err != nil {
+		return Table{}, err
+	}
+	return readTable(r, opts)
+}
+
+// readTable membaca CSV dari r dengan satu csv.Reader dan menyusunnya menjadi Table.
+func readTable(r io.Reader, opts CSVOptions) (Table, error) {
+	// Membuat pembaca CSV dari reader yang diberikan
+	reader := csv.NewReader(r)
+	// Gunakan delimiter yang diminta sebagai pemisah kolom
+	reader.Comma = opts.Delimiter
+	// Izinkan jumlah kolom yang berbeda di setiap baris; baris yang tidak
+	// rata ditangani secara eksplisit di bawah
+	reader.FieldsPerRecord = -1
+	// Pakai ulang slice record di setiap Read agar alokasi tetap kecil
+	reader.ReuseRecord = true
+
+	// Inisialisasi tabel dengan peta kosong
+	table := Table{Data: make(map[string][]string)}
+
+	// Mengambil baris pertama sebagai header
+	line, err := reader.Read()
+	if err == io.EOF {
+		// Jika tidak ada baris dalam data CSV, kembalikan tabel kosong
+		return table, nil
+	}
+	if err != nil {
+		return Table{}, fmt.Errorf("reading CSV header: %w", err)
+	}
+	// Salin header karena slice record akan dipakai ulang oleh reader, lalu
+	// bersihkan BOM dan spasi yang sering muncul di file hasil ekspor Excel
+	headers := cleanHeaders(line)
+	// Nama kolom yang sama akan saling menimpa di map, jadi beri nama unik
+	// atau tolak sesuai opsi
+	headers, err = uniqueHeaders(headers, opts.Duplicates)
+	if err != nil {
+		return Table{}, err
+	}
+	table.Columns = headers
+	for _, header := range headers {
+		// Inisialisasi setiap header dengan slice kosong dalam peta hasil
+		table.Data[header] = []string{}
+	}
+
+	// Baca baris data satu per satu sampai EOF
+	for {
+		line, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Table{}, fmt.Errorf("reading CSV: %w", err)
+		}
+
+		for i, header := range headers {
+			// Baris yang lebih pendek dari header diisi dengan string kosong,
+			// sedangkan kolom berlebih di luar header diabaikan
+			value := ""
+			if i < len(line) {
+				value = line[i]
+			}
+			// Menambahkan nilai ke dalam slice yang sesuai dengan header
+			table.Data[header] = append(table.Data[header], value)
+		}
+	}
+
+	// Mengembalikan tabel dan nil (tidak ada error)
+	return table, nil
+}
+
+// uniqueHeaders memastikan setiap nama di headers unik. Dengan
+// RenameDuplicates, kemunculan kedua dan seterusnya diberi akhiran _2, _3,
+// dan seterusnya yang belum dipakai kolom lain; dengan RejectDuplicates,
+// duplikat pertama dikembalikan sebagai error.
+func uniqueHeaders(headers []string, mode DuplicateHeaders) ([]string, error) {
+	taken := make(map[string]bool, len(headers))
+	for _, header := range headers {
+		taken[header] = true
+	}
+
+	seen := make(map[string]int, len(headers))
+	for i, header := range headers {
+		first, ok := seen[header]
+		if !ok {
+			seen[header] = i
+			continue
+		}
+		if mode == RejectDuplicates {
+			return nil, fmt.Errorf("%w: %q is used by columns %d and %d", ErrDuplicateHeader, header, first+1, i+1)
+		}
+
+		// Cari akhiran pertama yang belum dipakai, misal jika CSV sudah
+		// memiliki kolom "Name_2"
+		for n := 2; ; n++ {
+			name := fmt.Sprintf("%s_%d", header, n)
+			if !taken[name] {
+				taken[name] = true
+				headers[i] = name
+				break
+			}
+		}
+	}
+	return headers, nil
+}
+
+// cleanHeaders mengembalikan salinan headers tanpa UTF-8 BOM di awal kolom
+// pertama dan tanpa spasi di sekitar setiap nama kolom.
+func cleanHeaders(headers []string) []string {
+	result := make([]string, len(headers))
+	for i, header := range headers {
+		if i == 0 {
+			header = strings.TrimPrefix(header, "\uFEFF")
+		}
+		result[i] = strings.TrimSpace(header)
+	}
+	return result
+}
+
+// FlattenTable mengubah t menjadi teks biasa untuk model berbasis teks seperti
+// summarization, satu baris per record dengan format "Kolom: nilai, ...".
+// Urutan kolom mengikuti t.Columns.
+func FlattenTable(t Table) string {
+	var b strings.Builder
+	for row := 0; row < rowCount(t); row++ {
+		for i, column := range t.Columns {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			value := ""
+			if values := t.Data[column]; row < len(values) {
+				value = values[row]
+			}
+			b.WriteString(column + ": " + value)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// WriteCSV menulis t ke w sebagai CSV dengan header sesuai t.Columns. Nilai
+// ditulis apa adanya (misal "1.20" tetap "1.20"), dan nilai yang mengandung
+// koma, tanda kutip, atau baris baru dikutip oleh csv.Writer sehingga hasilnya
+// bisa dibaca kembali dengan CsvToTable tanpa berubah.
+func WriteCSV(w io.Writer, t Table) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(t.Columns); err != nil {
+		return err
+	}
+
+	// Kolom yang lebih pendek diisi string kosong, sama seperti readTable
+	for _, record := range t.Rows() {
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// TableToCsv sama dengan WriteCSV, tetapi mengembalikan CSV sebagai string.
+func TableToCsv(t Table) (string, error) {
+	var b strings.Builder
+	if err := WriteCSV(&b, t); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// FormatTable menulis t ke w sebagai tabel dengan kolom yang rata, untuk
+// memeriksa hasil parsing CSV. Hanya maxRows baris pertama yang ditulis,
+// diikuti jumlah baris yang tidak ditampilkan; maxRows <= 0 berarti semua baris.
+func FormatTable(w io.Writer, t Table, maxRows int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.Columns, "\t"))
+
+	rows := rowCount(t)
+	shown := rows
+	if maxRows > 0 && maxRows < rows {
+		shown = maxRows
+	}
+	for row := 0; row < shown; row++ {
+		fmt.Fprintln(tw, strings.Join(t.row(row), "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if shown < rows {
+		_, err := fmt.Fprintf(w, "... %d more rows\n", rows-shown)
+		return err
+	}
+	return nil
+}
+
+// rowCount mengembalikan jumlah baris kolom terpanjang di t.
+func rowCount(t Table) int {
+	rows := 0
+	for _, column := range t.Columns {
+		if n := len(t.Data[column]); n > rows {
+			rows = n
+		}
+	}
+	return rows
+}
@@ -0,0 +1,55 @@
+package tableqa_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"testing"
+
+	"a21hc3NpZ25tZW50/tableqa"
+)
+
+// benchmarkCSV membuat data CSV berukuran beberapa megabyte dengan bentuk yang
+// sama seperti data-series.csv.
+func benchmarkCSV() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("Date,Time,Appliance,Energy_Consumption,Room,Status\n")
+	for i := 0; i < 100000; i++ {
+		fmt.Fprintf(&buf, "2022-01-%02d,%02d:00,Refrigerator,1.2,Kitchen,On\n", i%28+1, i%24)
+	}
+	return buf.Bytes()
+}
+
+// BenchmarkCsvToSliceViaString mengukur cara lama di main: ReadAll, gabungkan
+// ulang menjadi string, lalu parse lagi dengan CsvToSlice.
+func BenchmarkCsvToSliceViaString(b *testing.B) {
+	data := benchmarkCSV()
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lines, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			b.Fatal(err)
+		}
+		var sb strings.Builder
+		for _, line := range lines {
+			sb.WriteString(strings.Join(line, ",") + "\n")
+		}
+		if _, err := tableqa.CsvToSlice(sb.String()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCsvReaderToSlice mengukur pembacaan langsung dari io.Reader.
+func BenchmarkCsvReaderToSlice(b *testing.B) {
+	data := benchmarkCSV()
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := tableqa.CsvReaderToSlice(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
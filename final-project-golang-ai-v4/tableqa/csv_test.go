@@ -0,0 +1,365 @@
+package tableqa_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CSV", func() {
+	Describe("csvToSlice", func() {
+		DescribeTable("converts valid CSV data",
+			func(data string, expected map[string][]string) {
+				result, err := tableqa.CsvToSlice(data)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(result).Should(Equal(expected))
+			},
+			Entry("empty input", "", map[string][]string{}),
+			Entry("header only", "Name,Age\n", map[string][]string{
+				"Name": {},
+				"Age":  {},
+			}),
+			Entry("quoted fields containing commas", "Name,City\n\"Smith, John\",\"Paris, FR\"", map[string][]string{
+				"Name": {"Smith, John"},
+				"City": {"Paris, FR"},
+			}),
+			Entry("fields with embedded newlines", "Name,Note\nJohn,\"line one\nline two\"", map[string][]string{
+				"Name": {"John"},
+				"Note": {"line one\nline two"},
+			}),
+			Entry("multiple rows", "Name,Age\nJohn,30\nDoe,40\nJane,25", map[string][]string{
+				"Name": {"John", "Doe", "Jane"},
+				"Age":  {"30", "40", "25"},
+			}),
+		)
+
+		It("returns an error for malformed quoting", func() {
+			_, err := tableqa.CsvToSlice("Name,Age\n\"John,30")
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("wraps the csv.ParseError so callers can inspect it", func() {
+			_, err := tableqa.CsvToSlice("Name,Age\nJo\"hn,30")
+			Expect(errors.Is(err, csv.ErrBareQuote)).Should(BeTrue())
+
+			var parseErr *csv.ParseError
+			Expect(errors.As(err, &parseErr)).Should(BeTrue())
+			Expect(parseErr.Line).Should(Equal(2))
+		})
+	})
+
+	Describe("header cleanup", func() {
+		It("strips a leading UTF-8 BOM from the first header", func() {
+			result, err := tableqa.CsvToSlice("\uFEFFName,Age\nJohn,30")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result).Should(Equal(map[string][]string{
+				"Name": {"John"},
+				"Age":  {"30"},
+			}))
+		})
+
+		It("trims whitespace around headers but not values", func() {
+			table, err := tableqa.CsvToTable(" Name , Age\n John ,30")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(table.Columns).Should(Equal([]string{"Name", "Age"}))
+			Expect(table.Data["Name"]).Should(Equal([]string{" John "}))
+		})
+	})
+
+	Describe("csvReaderToSlice", func() {
+		It("reads CSV directly from an io.Reader", func() {
+			result, err := tableqa.CsvReaderToSlice(strings.NewReader("Name,Age\nJohn,30\nDoe,40"))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result).Should(Equal(map[string][]string{
+				"Name": {"John", "Doe"},
+				"Age":  {"30", "40"},
+			}))
+		})
+
+		It("returns an empty map for an empty reader", func() {
+			result, err := tableqa.CsvReaderToSlice(strings.NewReader(""))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result).Should(BeEmpty())
+		})
+	})
+
+	Describe("csvToTable", func() {
+		It("keeps the columns in header order", func() {
+			data := "Zeta,Alpha,Mid\n1,2,3\n4,5,6"
+			table, err := tableqa.CsvToTable(data)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(table.Columns).Should(Equal([]string{"Zeta", "Alpha", "Mid"}))
+			Expect(table.Data).Should(Equal(map[string][]string{
+				"Zeta":  {"1", "4"},
+				"Alpha": {"2", "5"},
+				"Mid":   {"3", "6"},
+			}))
+		})
+
+		It("returns an empty table for empty input", func() {
+			table, err := tableqa.CsvToTable("")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(table.Columns).Should(BeEmpty())
+			Expect(table.Data).Should(BeEmpty())
+		})
+	})
+
+	Describe("csvToSlice with ragged rows", func() {
+		It("pads rows that are shorter than the header", func() {
+			data := "Name,Age,City\nJohn,30\nDoe,40,Paris"
+			result, err := tableqa.CsvToSlice(data)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result).Should(Equal(map[string][]string{
+				"Name": {"John", "Doe"},
+				"Age":  {"30", "40"},
+				"City": {"", "Paris"},
+			}))
+		})
+
+		It("ignores columns beyond the header", func() {
+			data := "Name,Age\nJohn,30,extra\nDoe,40"
+			result, err := tableqa.CsvToSlice(data)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result).Should(Equal(map[string][]string{
+				"Name": {"John", "Doe"},
+				"Age":  {"30", "40"},
+			}))
+		})
+	})
+
+	Describe("duplicate headers", func() {
+		It("renames repeated headers so no data is lost", func() {
+			table, err := tableqa.CsvToTable("Name,Age,Name,Name\nJohn,30,Smith,Jr\nJane,25,Doe,\n")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(table.Columns).Should(Equal([]string{"Name", "Age", "Name_2", "Name_3"}))
+			Expect(table.Data).Should(Equal(map[string][]string{
+				"Name":   {"John", "Jane"},
+				"Age":    {"30", "25"},
+				"Name_2": {"Smith", "Doe"},
+				"Name_3": {"Jr", ""},
+			}))
+		})
+
+		It("skips suffixes already used by another column", func() {
+			table, err := tableqa.CsvToTable("Name,Name_2,Name\na,b,c\n")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(table.Columns).Should(Equal([]string{"Name", "Name_2", "Name_3"}))
+			Expect(table.Data["Name_3"]).Should(Equal([]string{"c"}))
+		})
+
+		It("treats headers that differ only by surrounding spaces as duplicates", func() {
+			table, err := tableqa.CsvToTable("Name, Name\na,b\n")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(table.Columns).Should(Equal([]string{"Name", "Name_2"}))
+		})
+
+		It("rejects repeated headers with RejectDuplicates", func() {
+			_, err := tableqa.ReadTable(strings.NewReader("Name,Age,Name\nJohn,30,Smith\n"), tableqa.CSVOptions{Duplicates: tableqa.RejectDuplicates})
+			Expect(err).Should(MatchError(tableqa.ErrDuplicateHeader))
+			Expect(err).Should(MatchError(`duplicate column header: "Name" is used by columns 1 and 3`))
+		})
+	})
+
+	Describe("readTable", func() {
+		It("defaults to a comma delimiter", func() {
+			table, err := tableqa.ReadTable(strings.NewReader("Name,Age\nJohn,30\n"), tableqa.CSVOptions{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(table.Columns).Should(Equal([]string{"Name", "Age"}))
+		})
+
+		It("uses the given delimiter", func() {
+			table, err := tableqa.ReadTable(strings.NewReader("Name;Age\nJohn;30\n"), tableqa.CSVOptions{Delimiter: ';'})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(table.Data["Age"]).Should(Equal([]string{"30"}))
+		})
+
+		It("transcodes Latin-1 input to UTF-8", func() {
+			latin1 := "Caf\xe9,Ma\xf1ana\nCr\xe8me br\xfbl\xe9e,Jos\xe9\n"
+			table, err := tableqa.ReadTable(strings.NewReader(latin1), tableqa.CSVOptions{Encoding: tableqa.EncodingLatin1})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(table.Columns).Should(Equal([]string{"Café", "Mañana"}))
+			Expect(table.Data["Café"]).Should(Equal([]string{"Crème brûlée"}))
+			Expect(table.Data["Mañana"]).Should(Equal([]string{"José"}))
+		})
+
+		It("rejects an unknown encoding", func() {
+			_, err := tableqa.ReadTable(strings.NewReader("Name\nJohn\n"), tableqa.CSVOptions{Encoding: "ebcdic"})
+			Expect(err).Should(MatchError(`unknown encoding "ebcdic" (want "utf-8" or "latin1")`))
+		})
+	})
+
+	Describe("csvToSliceWithDelimiter", func() {
+		It("parses semicolon separated data", func() {
+			data := "Name;Age\nJohn;30\nDoe;40"
+			result, err := tableqa.CsvToSliceWithDelimiter(data, ';')
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result).Should(Equal(map[string][]string{
+				"Name": {"John", "Doe"},
+				"Age":  {"30", "40"},
+			}))
+		})
+
+		It("parses tab separated data", func() {
+			data := "Name\tCity\nJohn\tNew York, NY"
+			result, err := tableqa.CsvToSliceWithDelimiter(data, '\t')
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result).Should(Equal(map[string][]string{
+				"Name": {"John"},
+				"City": {"New York, NY"},
+			}))
+		})
+
+		It("rejects an invalid delimiter", func() {
+			_, err := tableqa.CsvToSliceWithDelimiter("Name,Age\nJohn,30", utf8.RuneError)
+			Expect(err).Should(HaveOccurred())
+
+			_, err = tableqa.CsvToSliceWithDelimiter("Name,Age\nJohn,30", '"')
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("flattenTable", func() {
+		It("writes one line per row in column order", func() {
+			table, err := tableqa.CsvReaderToTable(strings.NewReader("Appliance,Energy_Consumption\nTV,0.8\nRefrigerator,1.2"))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(tableqa.FlattenTable(table)).Should(Equal(
+				"Appliance: TV, Energy_Consumption: 0.8\n" +
+					"Appliance: Refrigerator, Energy_Consumption: 1.2\n"))
+		})
+
+		It("returns an empty string for an empty table", func() {
+			Expect(tableqa.FlattenTable(tableqa.Table{})).Should(BeEmpty())
+		})
+	})
+
+	Describe("table.Dimensions", func() {
+		DescribeTable("counts rows and columns",
+			func(data string, rows, cols int) {
+				t, err := tableqa.CsvToTable(data)
+				Expect(err).ShouldNot(HaveOccurred())
+				r, c := t.Dimensions()
+				Expect(r).Should(Equal(rows))
+				Expect(c).Should(Equal(cols))
+			},
+			Entry("a normal table", "Name,Age,City\nJohn,30,Jakarta\nJane,25,Bandung", 2, 3),
+			Entry("a header without rows", "Name,Age", 0, 2),
+			Entry("an empty table", "", 0, 0),
+		)
+	})
+
+	Describe("table.Rows", func() {
+		It("transposes the columns into rows in column order", func() {
+			t := tableqa.Table{
+				Columns: []string{"Name", "Age"},
+				Data:    map[string][]string{"Age": {"30", "25"}, "Name": {"John", "Jane"}},
+			}
+			Expect(t.Rows()).Should(Equal([][]string{{"John", "30"}, {"Jane", "25"}}))
+			Expect(t.RowMaps()).Should(Equal([]map[string]string{
+				{"Name": "John", "Age": "30"},
+				{"Name": "Jane", "Age": "25"},
+			}))
+		})
+
+		It("pads ragged columns with empty values", func() {
+			t := tableqa.Table{
+				Columns: []string{"Name", "Age", "City"},
+				Data:    map[string][]string{"Name": {"John", "Jane", "Doe"}, "Age": {"30"}, "City": {}},
+			}
+			Expect(t.Rows()).Should(Equal([][]string{{"John", "30", ""}, {"Jane", "", ""}, {"Doe", "", ""}}))
+			Expect(t.RowMaps()[1]).Should(Equal(map[string]string{"Name": "Jane", "Age": "", "City": ""}))
+		})
+
+		It("returns no rows for an empty table", func() {
+			Expect(tableqa.Table{}.Rows()).Should(BeEmpty())
+			Expect(tableqa.Table{}.RowMaps()).Should(BeEmpty())
+		})
+	})
+
+	Describe("writeCSV", func() {
+		It("quotes fields with commas, quotes and newlines so they round-trip", func() {
+			table := tableqa.Table{
+				Columns: []string{"Name", "Note", "Amount"},
+				Data: map[string][]string{
+					"Name":   {"Smith, John", "Jane"},
+					"Note":   {`said "hi"`, "line one\nline two"},
+					"Amount": {"1.20", "007"},
+				},
+			}
+
+			csvData, err := tableqa.TableToCsv(table)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(csvData).Should(Equal("Name,Note,Amount\n" +
+				"\"Smith, John\",\"said \"\"hi\"\"\",1.20\n" +
+				"Jane,\"line one\nline two\",007\n"))
+
+			parsed, err := tableqa.CsvToTable(csvData)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(parsed).Should(Equal(table))
+		})
+
+		It("pads short columns with empty values", func() {
+			var out bytes.Buffer
+			Expect(tableqa.WriteCSV(&out, tableqa.Table{
+				Columns: []string{"Name", "Age"},
+				Data:    map[string][]string{"Name": {"John", "Jane"}, "Age": {"30"}},
+			})).Should(Succeed())
+			Expect(out.String()).Should(Equal("Name,Age\nJohn,30\nJane,\n"))
+		})
+	})
+
+	Describe("checkTableData", func() {
+		DescribeTable("rejects a CSV without data",
+			func(data string) {
+				table, err := tableqa.CsvToTable(data)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(tableqa.CheckTableData(table)).Should(MatchError(tableqa.ErrNoData))
+			},
+			Entry("empty string", ""),
+			Entry("header only", "Name,Age\n"),
+		)
+
+		It("accepts a table with at least one row", func() {
+			table, err := tableqa.CsvToTable("Name,Age\nJohn,30")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(tableqa.CheckTableData(table)).Should(Succeed())
+		})
+	})
+
+	Describe("formatTable", func() {
+		table := tableqa.Table{
+			Columns: []string{"Appliance", "Energy_Consumption", "Room"},
+			Data: map[string][]string{
+				"Appliance":          {"Refrigerator", "TV", "Fan"},
+				"Energy_Consumption": {"1.2", "0.8", "0.1"},
+				"Room":               {"Kitchen", "Living Room", "Bedroom"},
+			},
+		}
+
+		It("aligns the columns of every row", func() {
+			var out bytes.Buffer
+			Expect(tableqa.FormatTable(&out, table, 0)).Should(Succeed())
+			Expect(out.String()).Should(Equal("" +
+				"Appliance     Energy_Consumption  Room\n" +
+				"Refrigerator  1.2                 Kitchen\n" +
+				"TV            0.8                 Living Room\n" +
+				"Fan           0.1                 Bedroom\n"))
+		})
+
+		It("stops after maxRows and reports the rest", func() {
+			var out bytes.Buffer
+			Expect(tableqa.FormatTable(&out, table, 2)).Should(Succeed())
+			Expect(out.String()).Should(Equal("" +
+				"Appliance     Energy_Consumption  Room\n" +
+				"Refrigerator  1.2                 Kitchen\n" +
+				"TV            0.8                 Living Room\n" +
+				"... 1 more rows\n"))
+		})
+	})
+})
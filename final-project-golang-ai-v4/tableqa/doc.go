@@ -0,0 +1,8 @@
+// Package tableqa berisi logika untuk mengajukan pertanyaan tentang data tabel
+// (CSV) ke model table-question-answering seperti TAPAS melalui Hugging Face
+// Inference API.
+//
+// CsvToSlice dan CsvToTable mengubah data CSV menjadi tabel per kolom, Inputs
+// membungkus tabel beserta pertanyaannya, dan AIModelConnector mengirim Inputs
+// ke model lalu mengembalikan Response.
+package tableqa
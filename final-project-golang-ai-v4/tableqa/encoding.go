@@ -0,0 +1,48 @@
+package tableqa
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+// Encoding adalah encoding karakter file CSV.
+type Encoding string
+
+const (
+	// EncodingUTF8 membaca CSV apa adanya. Ini encoding default.
+	EncodingUTF8 Encoding = "utf-8"
+	// EncodingLatin1 mengubah CSV ISO-8859-1 ke UTF-8 sebelum dibaca, untuk
+	// file lama atau hasil ekspor Excel yang tidak disimpan sebagai UTF-8.
+	EncodingLatin1 Encoding = "latin1"
+)
+
+// ParseEncoding mengubah nama encoding, misal nilai flag, menjadi Encoding.
+// String kosong berarti EncodingUTF8; "utf8", "latin-1" dan "iso-8859-1"
+// juga diterima, tanpa membedakan huruf besar.
+func ParseEncoding(s string) (Encoding, error) {
+	switch strings.ToLower(s) {
+	case "", "utf-8", "utf8":
+		return EncodingUTF8, nil
+	case "latin1", "latin-1", "iso-8859-1":
+		return EncodingLatin1, nil
+	default:
+		return "", fmt.Errorf("unknown encoding %q (want %q or %q)", s, EncodingUTF8, EncodingLatin1)
+	}
+}
+
+// DecodeReader mengembalikan reader yang menghasilkan isi r dalam UTF-8.
+// Untuk EncodingUTF8 r dikembalikan apa adanya.
+func DecodeReader(r io.Reader, enc Encoding) (io.Reader, error) {
+	enc, err := ParseEncoding(string(enc))
+	if err != nil {
+		return nil, err
+	}
+	if enc == EncodingLatin1 {
+		return transform.NewReader(r, charmap.ISO8859_1.NewDecoder()), nil
+	}
+	return r, nil
+}
@@ -0,0 +1,238 @@
+package tableqa
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxErrorBodyLen membatasi panjang body respons yang disertakan dalam APIError.
+const maxErrorBodyLen = 512
+
+// ErrUnauthorized cocok dengan *APIError berstatus 401 atau 403, yaitu ketika
+// Hugging Face menolak token atau token tidak punya akses ke model.
+var ErrUnauthorized = errors.New("unauthorized: check that HUGGINGFACE_TOKEN is set to a valid token with access to this model")
+
+// APIError dikembalikan oleh ConnectAIModel ketika API membalas dengan status
+// selain 200. Gunakan errors.As untuk memeriksa status dan pesan dari server.
+// Untuk status 401 dan 403, errors.Is(err, ErrUnauthorized) bernilai true.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("failed to connect to AI model with status: %d", e.StatusCode)
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	if e.unauthorized() {
+		msg += " (" + ErrUnauthorized.Error() + ")"
+	}
+	return msg
+}
+
+func (e *APIError) Is(target error) bool {
+	return target == ErrUnauthorized && e.unauthorized()
+}
+
+// unauthorized melaporkan apakah status e berarti token ditolak.
+func (e *APIError) unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// SchemaError dikembalikan oleh ConnectAIModel ketika API membalas dengan
+// JSON yang tidak berbentuk jawaban TAPAS, misal karena Model menunjuk ke
+// model summarization atau classification.
+type SchemaError struct {
+	// Model adalah ID model yang membalas
+	Model string
+	// Body adalah body respons apa adanya, untuk diagnosis
+	Body string
+	// Err adalah error decoding dari encoding/json jika ada, misal
+	// *json.UnmarshalTypeError. Nil jika JSON ter-decode tetapi semua field kosong.
+	Err error
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("response did not match expected schema for model %s: %s", e.Model, truncate(e.Body, maxErrorBodyLen))
+}
+
+func (e *SchemaError) Unwrap() error {
+	return e.Err
+}
+
+// TimeoutError dikembalikan oleh ConnectAIModel ketika permintaan melewati
+// batas waktu, misal karena model besar lambat dimuat. errors.Is(err,
+// context.DeadlineExceeded) tetap bernilai true untuk error ini.
+type TimeoutError struct {
+	// After adalah lama permintaan berjalan sebelum dihentikan
+	After time.Duration
+	// Err adalah error aslinya, yang membungkus context.DeadlineExceeded
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("request timed out after %v; try increasing -timeout", e.After)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// maxSnippetLen membatasi panjang awal body yang disertakan dalam NonJSONError.
+const maxSnippetLen = 200
+
+// NonJSONError dikembalikan oleh ConnectAIModel ketika body respons bukan
+// JSON, misal halaman error HTML dari proxy atau gateway. Gunakan errors.As
+// untuk melihat awal body-nya.
+type NonJSONError struct {
+	// Body adalah paling banyak maxSnippetLen byte pertama body respons
+	Body string
+	// Err adalah error dari encoding/json, biasanya *json.SyntaxError
+	Err error
+}
+
+func (e *NonJSONError) Error() string {
+	return fmt.Sprintf("received non-JSON response (%v); body starts with %q", e.Err, e.Body)
+}
+
+func (e *NonJSONError) Unwrap() error {
+	return e.Err
+}
+
+// ErrPayloadTooLarge dibungkus oleh PayloadTooLargeError.
+var ErrPayloadTooLarge = errors.New("payload too large")
+
+// PayloadTooLargeError dikembalikan oleh ConnectAIModel ketika body JSON
+// melebihi AIModelConnector.MaxPayloadBytes. errors.Is(err,
+// ErrPayloadTooLarge) bernilai true untuk error ini.
+type PayloadTooLargeError struct {
+	// Size adalah ukuran body JSON dalam byte
+	Size int
+	// Limit adalah batas yang berlaku
+	Limit int
+}
+
+func (e *PayloadTooLargeError) Error() string {
+	return fmt.Sprintf("payload of %d bytes exceeds limit %d; reduce rows or columns", e.Size, e.Limit)
+}
+
+func (e *PayloadTooLargeError) Unwrap() error {
+	return ErrPayloadTooLarge
+}
+
+// ErrTooManyColumns dibungkus oleh TooManyColumnsError.
+var ErrTooManyColumns = errors.New("too many columns")
+
+// TooManyColumnsError dikembalikan oleh ConnectAIModel ketika tabel memiliki
+// lebih banyak kolom dari AIModelConnector.MaxColumns. errors.Is(err,
+// ErrTooManyColumns) bernilai true untuk error ini.
+type TooManyColumnsError struct {
+	// Columns adalah jumlah kolom tabel
+	Columns int
+	// Limit adalah batas yang berlaku
+	Limit int
+}
+
+func (e *TooManyColumnsError) Error() string {
+	return fmt.Sprintf("table has %d columns, more than the limit of %d; select a subset of columns (e.g. with -columns or SelectColumns)", e.Columns, e.Limit)
+}
+
+func (e *TooManyColumnsError) Unwrap() error {
+	return ErrTooManyColumns
+}
+
+// ColumnLengthError dikembalikan oleh Inputs.Validate ketika sebuah kolom
+// tidak sama panjang dengan kolom lainnya. errors.Is(err, ErrRaggedTable)
+// bernilai true untuk error ini.
+type ColumnLengthError struct {
+	// Column adalah nama kolom yang panjangnya menyimpang
+	Column string
+	// Rows adalah jumlah baris di Column
+	Rows int
+	// Expected adalah jumlah baris yang dimiliki sebagian besar kolom lain
+	Expected int
+}
+
+func (e *ColumnLengthError) Error() string {
+	return fmt.Sprintf("%v: column %q has %d rows, expected %d", ErrRaggedTable, e.Column, e.Rows, e.Expected)
+}
+
+func (e *ColumnLengthError) Unwrap() error {
+	return ErrRaggedTable
+}
+
+// BatchError dikembalikan oleh ConnectAIModelBatch ketika satu atau lebih
+// query gagal. Errors sejajar dengan queries: Errors[i] nil jika query ke-i
+// berhasil.
+type BatchError struct {
+	Errors []error
+}
+
+// Failed mengembalikan jumlah query yang gagal.
+func (e *BatchError) Failed() int {
+	n := 0
+	for _, err := range e.Errors {
+		if err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (e *BatchError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d queries failed", e.Failed(), len(e.Errors))
+	for i, err := range e.Errors {
+		if err != nil {
+			fmt.Fprintf(&b, "\n  query %d: %v", i, err)
+		}
+	}
+	return b.String()
+}
+
+// Is melaporkan apakah salah satu kegagalan cocok dengan target, sehingga
+// errors.Is(err, context.Canceled) bekerja sejak Go 1.18; Unwrap() []error
+// baru dikenali errors.Is mulai Go 1.20.
+func (e *BatchError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if err != nil && errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As mengisi target dengan kegagalan pertama yang cocok, dengan alasan yang
+// sama seperti Is.
+func (e *BatchError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if err != nil && errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap mengembalikan error yang tidak nil untuk kode yang memakai
+// multi-error Go 1.20 secara langsung.
+func (e *BatchError) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Errors {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// truncate memotong s menjadi paling banyak n byte dan menandai potongannya.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
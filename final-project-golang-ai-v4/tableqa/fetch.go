@@ -0,0 +1,68 @@
+package tableqa
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultMaxCSVBytes adalah batas ukuran CSV yang diunduh FetchCSV ketika
+// maxBytes tidak diisi.
+const DefaultMaxCSVBytes = 32 << 20
+
+// ErrCSVTooLarge dikembalikan oleh FetchCSV ketika body respons melebihi
+// batas ukuran.
+var ErrCSVTooLarge = errors.New("CSV is too large")
+
+// FetchCSV mengunduh CSV dari url dengan client lalu membacanya menjadi
+// Table. Status selain 200 dikembalikan sebagai error, dan body yang lebih
+// besar dari maxBytes ditolak dengan ErrCSVTooLarge agar dataset yang sangat
+// besar tidak dimuat seluruhnya ke memori. Nilai maxBytes <= 0 berarti
+// DefaultMaxCSVBytes. Jika client nil, http.DefaultClient yang digunakan.
+func FetchCSV(ctx context.Context, client HTTPDoer, url string, maxBytes int64) (Table, error) {
+	return FetchCSVWithDelimiter(ctx, client, url, maxBytes, ',')
+}
+
+// FetchCSVWithDelimiter sama dengan FetchCSV, tetapi memakai delim sebagai
+// pemisah kolom, misal '\t' untuk file TSV.
+func FetchCSVWithDelimiter(ctx context.Context, client HTTPDoer, url string, maxBytes int64, delim rune) (Table, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxCSVBytes
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return Table{}, err
+	}
+	req.Header.Set("User-Agent", DefaultUserAgent)
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Table{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Table{}, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	// Tolak lebih awal jika server sudah memberi tahu ukurannya
+	if resp.ContentLength > maxBytes {
+		return Table{}, fmt.Errorf("%w: %d bytes, limit is %d", ErrCSVTooLarge, resp.ContentLength, maxBytes)
+	}
+
+	// Baca satu byte lebih dari batas untuk mengetahui apakah body terpotong
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return Table{}, err
+	}
+	if int64(len(data)) > maxBytes {
+		return Table{}, fmt.Errorf("%w: more than %d bytes", ErrCSVTooLarge, maxBytes)
+	}
+	return CsvReaderToTableWithDelimiter(bytes.NewReader(data), delim)
+}
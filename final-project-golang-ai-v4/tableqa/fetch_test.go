@@ -0,0 +1,60 @@
+package tableqa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("fetchCSV", func() {
+	const csvData = "Appliance,Energy_Consumption\nRefrigerator,1.2\nTV,0.8\n"
+
+	var server *httptest.Server
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/data.csv", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/csv")
+			w.Write([]byte(csvData))
+		})
+		mux.HandleFunc("/stream.csv", func(w http.ResponseWriter, r *http.Request) {
+			// Flush sebelum selesai agar Content-Length tidak dikirim
+			w.Write([]byte(csvData))
+			w.(http.Flusher).Flush()
+			w.Write([]byte(strings.Repeat("Fan,0.1\n", 10)))
+		})
+		server = httptest.NewServer(mux)
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("reads the CSV served at the URL", func() {
+		table, err := tableqa.FetchCSV(context.Background(), server.Client(), server.URL+"/data.csv", 0)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(table.Columns).Should(Equal([]string{"Appliance", "Energy_Consumption"}))
+		Expect(table.Data).Should(Equal(map[string][]string{
+			"Appliance":          {"Refrigerator", "TV"},
+			"Energy_Consumption": {"1.2", "0.8"},
+		}))
+	})
+
+	It("reports a non-200 status", func() {
+		_, err := tableqa.FetchCSV(context.Background(), server.Client(), server.URL+"/missing.csv", 0)
+		Expect(err).Should(MatchError("unexpected status 404"))
+	})
+
+	DescribeTable("rejects bodies over the size limit",
+		func(path string) {
+			_, err := tableqa.FetchCSV(context.Background(), server.Client(), server.URL+path, 10)
+			Expect(err).Should(MatchError(tableqa.ErrCSVTooLarge))
+		},
+		Entry("with Content-Length", "/data.csv"),
+		Entry("without Content-Length", "/stream.csv"),
+	)
+})
@@ -0,0 +1,64 @@
+package tableqa
+
+import "strings"
+
+// Query adalah pertanyaan beserta metadata terstruktur. Filters menyaring
+// baris tabel sebelum dikirim ke model, misal {"Region": "EU"} agar model
+// hanya melihat baris untuk region EU.
+type Query struct {
+	Text string `json:"text"`
+	// Filters memetakan nama kolom ke nilai yang harus dimiliki baris.
+	// Kosong berarti semua baris dipakai.
+	Filters map[string]string `json:"filters,omitempty"`
+}
+
+// Inputs mengembalikan Inputs untuk q atas table yang sudah disaring dengan
+// ApplyFilters, siap dikirim dengan ConnectAIModel.
+func (q Query) Inputs(table map[string][]string) Inputs {
+	return Inputs{Table: ApplyFilters(table, q.Filters), Query: q.Text}
+}
+
+// ApplyFilters mengembalikan salinan table yang hanya berisi baris dengan
+// nilai sama dengan filters di setiap kolom yang disebut. Nilai dibandingkan
+// tanpa membedakan huruf besar-kecil dan tanpa spasi di awal dan akhir.
+// Filter untuk kolom yang tidak ada tidak cocok dengan baris mana pun.
+// Tanpa filter, table dikembalikan apa adanya. Map asli tidak diubah.
+func ApplyFilters(table map[string][]string, filters map[string]string) map[string][]string {
+	if len(filters) == 0 {
+		return table
+	}
+
+	rows := 0
+	for _, values := range table {
+		if len(values) > rows {
+			rows = len(values)
+		}
+	}
+
+	// Tandai baris yang lolos semua filter
+	keep := make([]bool, rows)
+	for i := range keep {
+		keep[i] = true
+	}
+	for column, want := range filters {
+		values := table[column]
+		want = strings.TrimSpace(want)
+		for i := range keep {
+			if i >= len(values) || !strings.EqualFold(strings.TrimSpace(values[i]), want) {
+				keep[i] = false
+			}
+		}
+	}
+
+	result := make(map[string][]string, len(table))
+	for column, values := range table {
+		filtered := make([]string, 0, len(values))
+		for i, v := range values {
+			if keep[i] {
+				filtered = append(filtered, v)
+			}
+		}
+		result[column] = filtered
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package tableqa_test
+
+import (
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("applyFilters", func() {
+	table := map[string][]string{
+		"Appliance": {"Refrigerator", "TV", "Heater", "Fan"},
+		"Region":    {"EU", "US", "EU", "eu "},
+		"Room":      {"Kitchen", "Living Room", "Bedroom", "Kitchen"},
+	}
+
+	DescribeTable("keeps only the matching rows in every column",
+		func(filters map[string]string, expected map[string][]string) {
+			Expect(tableqa.ApplyFilters(table, filters)).Should(Equal(expected))
+		},
+		Entry("a single filter, ignoring case and spaces", map[string]string{"Region": "EU"}, map[string][]string{
+			"Appliance": {"Refrigerator", "Heater", "Fan"},
+			"Region":    {"EU", "EU", "eu "},
+			"Room":      {"Kitchen", "Bedroom", "Kitchen"},
+		}),
+		Entry("multiple filters", map[string]string{"Region": "eu", "Room": "Kitchen"}, map[string][]string{
+			"Appliance": {"Refrigerator", "Fan"},
+			"Region":    {"EU", "eu "},
+			"Room":      {"Kitchen", "Kitchen"},
+		}),
+		Entry("no matching rows", map[string]string{"Region": "APAC"}, map[string][]string{
+			"Appliance": {},
+			"Region":    {},
+			"Room":      {},
+		}),
+		Entry("an unknown column", map[string]string{"Country": "DE"}, map[string][]string{
+			"Appliance": {},
+			"Region":    {},
+			"Room":      {},
+		}),
+	)
+
+	It("returns the table unchanged without filters", func() {
+		Expect(tableqa.ApplyFilters(table, nil)).Should(Equal(table))
+	})
+
+	It("does not modify the original table", func() {
+		tableqa.ApplyFilters(table, map[string]string{"Region": "US"})
+		Expect(table["Appliance"]).Should(HaveLen(4))
+	})
+
+	It("builds inputs from a structured query", func() {
+		inputs := tableqa.Query{Text: "Which appliance uses the most energy?", Filters: map[string]string{"Region": "US"}}.Inputs(table)
+		Expect(inputs).Should(Equal(tableqa.Inputs{
+			Table: map[string][]string{"Appliance": {"TV"}, "Region": {"US"}, "Room": {"Living Room"}},
+			Query: "Which appliance uses the most energy?",
+		}))
+	})
+})
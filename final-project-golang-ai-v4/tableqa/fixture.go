@@ -0,0 +1,136 @@
+package tableqa
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrNoFixture dikembalikan oleh ConnectAIModel dalam mode replay ketika
+// tidak ada jawaban yang direkam untuk pertanyaan tersebut.
+var ErrNoFixture = errors.New("no recorded response")
+
+// WithReplay menjawab setiap pertanyaan dari jawaban yang direkam di
+// FixtureFile di dir tanpa memanggil API, misal untuk demo tanpa jaringan.
+// Rekaman dicocokkan dengan teks pertanyaan, bukan isi tabel, sehingga
+// jangan dipakai bersama ConnectAIModelChunked.
+func WithReplay(dir string) Option {
+	return func(c *AIModelConnector) {
+		c.ReplayDir = dir
+	}
+}
+
+// WithRecord menyimpan setiap jawaban yang berhasil dari API ke dir agar bisa
+// diputar ulang dengan WithReplay.
+func WithRecord(dir string) Option {
+	return func(c *AIModelConnector) {
+		c.RecordDir = dir
+	}
+}
+
+// FixtureFile adalah nama file indeks rekaman di direktori WithReplay dan
+// WithRecord.
+const FixtureFile = "fixtures.json"
+
+// fixture adalah rekaman untuk satu pertanyaan di FixtureFile, dengan key
+// teks pertanyaan (tanpa spasi di awal dan akhir), misal:
+//
+//	{
+//	    "What is the age of John?": {
+//	        "response": {"answer": "30"},
+//	        "models": {"google/tapas-large-finetuned-wtq": {"answer": "30"}}
+//	    }
+//	}
+//
+// Models berisi jawaban per model (ditambah "@revision" jika Revision diisi)
+// dan diisi oleh WithRecord. Response adalah jawaban untuk model apa pun,
+// berguna untuk fixture demo yang ditulis sendiri. Saat replay, jawaban untuk
+// model yang dipakai didahulukan.
+type fixture struct {
+	Response *Response           `json:"response,omitempty"`
+	Models   map[string]Response `json:"models,omitempty"`
+}
+
+// fixtureMu melindungi FixtureFile dari record yang berjalan bersamaan,
+// misal dari ConnectAIModelBatch.
+var fixtureMu sync.Mutex
+
+// fixtureModel mengembalikan sub-key model di fixture untuk c.
+func (c *AIModelConnector) fixtureModel() string {
+	if c.Revision != "" {
+		return c.modelID() + "@" + c.Revision
+	}
+	return c.modelID()
+}
+
+// readFixtures membaca FixtureFile di dir. File yang belum ada dianggap kosong.
+func readFixtures(dir string) (map[string]fixture, error) {
+	path := filepath.Join(dir, FixtureFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]fixture{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading fixture: %w", err)
+	}
+
+	fixtures := map[string]fixture{}
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("decoding fixture %s: %w", path, err)
+	}
+	return fixtures, nil
+}
+
+// replay membaca jawaban yang direkam untuk query dan model dari dir.
+func replay(dir, model, query string) (Response, error) {
+	fixtureMu.Lock()
+	fixtures, err := readFixtures(dir)
+	fixtureMu.Unlock()
+	if err != nil {
+		return Response{}, err
+	}
+
+	f := fixtures[strings.TrimSpace(query)]
+	if response, ok := f.Models[model]; ok {
+		return response, nil
+	}
+	if f.Response != nil {
+		return *f.Response, nil
+	}
+	return Response{}, fmt.Errorf("%w for query %q in %s", ErrNoFixture, query, dir)
+}
+
+// record menyimpan jawaban untuk query dan model ke FixtureFile di dir,
+// membuat dir jika belum ada. Rekaman lain di file tetap dipertahankan.
+func record(dir, model, query string, response Response) error {
+	fixtureMu.Lock()
+	defer fixtureMu.Unlock()
+
+	fixtures, err := readFixtures(dir)
+	if err != nil {
+		return err
+	}
+	key := strings.TrimSpace(query)
+	f := fixtures[key]
+	if f.Models == nil {
+		f.Models = map[string]Response{}
+	}
+	f.Models[model] = response
+	fixtures[key] = f
+
+	data, err := json.MarshalIndent(fixtures, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding fixture: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating fixture directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, FixtureFile), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing fixture: %w", err)
+	}
+	return nil
+}
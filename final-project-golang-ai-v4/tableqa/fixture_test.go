@@ -0,0 +1,119 @@
+package tableqa_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("fixtures", func() {
+	var (
+		dir    string
+		calls  int
+		client tableqa.HTTPDoer
+	)
+
+	table := map[string][]string{"Name": {"John", "Jane"}, "Age": {"30", "25"}}
+
+	BeforeEach(func() {
+		dir = filepath.Join(GinkgoT().TempDir(), "fixtures")
+		calls = 0
+		client = DoerFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"answer": "30", "coordinates": [[0, 1]], "cells": ["30"], "aggregator": "NONE"}`)),
+			}, nil
+		})
+	})
+
+	ask := func(connector *tableqa.AIModelConnector, query string) (tableqa.Response, error) {
+		return connector.ConnectAIModel(context.Background(), tableqa.Inputs{Table: table, Query: query}, "token")
+	}
+
+	It("replays a recorded response without calling the API", func() {
+		recorded, err := ask(tableqa.NewAIModelConnector(tableqa.WithHTTPClient(client), tableqa.WithRecord(dir)), "What is the age of John?")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(calls).Should(Equal(1))
+
+		replayed, err := ask(tableqa.NewAIModelConnector(tableqa.WithHTTPClient(client), tableqa.WithReplay(dir)), "What is the age of John?")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(replayed).Should(Equal(recorded))
+		Expect(calls).Should(Equal(1))
+	})
+
+	It("records every query in one readable index", func() {
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(client), tableqa.WithRecord(dir))
+		_, err := ask(connector, "What is the age of John?")
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = ask(connector, " What is the age of Jane? ")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		data, err := os.ReadFile(filepath.Join(dir, tableqa.FixtureFile))
+		Expect(err).ShouldNot(HaveOccurred())
+		response := `{"answer": "30", "coordinates": [[0, 1]], "cells": ["30"], "aggregator": "NONE"}`
+		Expect(data).Should(MatchJSON(`{
+			"What is the age of John?": {"models": {"` + tableqa.DefaultModel + `": ` + response + `}},
+			"What is the age of Jane?": {"models": {"` + tableqa.DefaultModel + `": ` + response + `}}
+		}`))
+	})
+
+	It("replays the same query for a different table but keeps models apart", func() {
+		_, err := ask(tableqa.NewAIModelConnector(tableqa.WithHTTPClient(client), tableqa.WithRecord(dir)), "How many people are there?")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, err = tableqa.NewAIModelConnector(tableqa.WithReplay(dir)).ConnectAIModel(context.Background(), tableqa.Inputs{
+			Table: map[string][]string{"Name": {"Doe"}, "Age": {"40"}},
+			Query: "How many people are there?",
+		}, "token")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, err = ask(tableqa.NewAIModelConnector(tableqa.WithReplay(dir), tableqa.WithRevision("v2")), "How many people are there?")
+		Expect(errors.Is(err, tableqa.ErrNoFixture)).Should(BeTrue())
+	})
+
+	It("replays a hand-written fixture for any model", func() {
+		Expect(os.MkdirAll(dir, 0o755)).Should(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, tableqa.FixtureFile), []byte(`{
+			"What is the age of John?": {"response": {"answer": "30", "aggregator": "NONE"}}
+		}`), 0o644)).Should(Succeed())
+
+		replayed, err := ask(tableqa.NewAIModelConnector(tableqa.WithReplay(dir), tableqa.WithModel("google/tapas-large-finetuned-wtq")), "What is the age of John?")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(replayed).Should(Equal(tableqa.Response{Answer: "30", Aggregator: "NONE"}))
+		Expect(calls).Should(BeZero())
+	})
+
+	It("returns ErrNoFixture for a query that was not recorded", func() {
+		_, err := ask(tableqa.NewAIModelConnector(tableqa.WithHTTPClient(client), tableqa.WithRecord(dir)), "What is the age of John?")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, err = ask(tableqa.NewAIModelConnector(tableqa.WithHTTPClient(client), tableqa.WithReplay(dir)), "What is the age of Jane?")
+		Expect(errors.Is(err, tableqa.ErrNoFixture)).Should(BeTrue())
+		Expect(err.Error()).Should(ContainSubstring(`"What is the age of Jane?"`))
+		Expect(calls).Should(Equal(1))
+	})
+
+	It("does not record failed responses", func() {
+		failing := DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"error": "boom"}`)),
+			}, nil
+		})
+		_, err := ask(tableqa.NewAIModelConnector(tableqa.WithHTTPClient(failing), tableqa.WithRecord(dir)), "What is the age of John?")
+		Expect(err).Should(HaveOccurred())
+
+		_, err = ask(tableqa.NewAIModelConnector(tableqa.WithReplay(dir)), "What is the age of John?")
+		Expect(errors.Is(err, tableqa.ErrNoFixture)).Should(BeTrue())
+	})
+})
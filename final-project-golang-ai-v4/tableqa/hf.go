@@ -0,0 +1,192 @@
+package tableqa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	hf "github.com/hupe1980/go-huggingface"
+)
+
+// ErrNoSummary dikembalikan oleh Summarize ketika API membalas tanpa ringkasan.
+var ErrNoSummary = errors.New("no summary returned")
+
+// ErrNoClassification dikembalikan oleh ClassifyText ketika API membalas
+// tanpa label.
+var ErrNoClassification = errors.New("no classification returned")
+
+// ErrEmptyContext dikembalikan oleh AnswerQuestion ketika QAInputs.Context kosong.
+var ErrEmptyContext = errors.New("context is empty")
+
+// ErrNoAnswer dikembalikan oleh AnswerQuestion ketika API membalas tanpa
+// jawaban.
+var ErrNoAnswer = errors.New("no answer returned")
+
+// ErrNoTranslation dikembalikan oleh Translate ketika API membalas tanpa
+// terjemahan.
+var ErrNoTranslation = errors.New("no translation returned")
+
+// DefaultQAModel adalah model question-answering (ekstraktif) yang dipakai
+// AnswerQuestion.
+const DefaultQAModel = "deepset/roberta-base-squad2"
+
+// Summarizer adalah bagian dari *hf.InferenceClient yang dibutuhkan Summarize,
+// sehingga test bisa memakai client tiruan.
+type Summarizer interface {
+	Summarization(ctx context.Context, req *hf.SummarizationRequest) (hf.SummarizationResponse, error)
+}
+
+// Summarize meringkas text dengan endpoint summarization dan mengembalikan
+// ringkasan pertama dari API.
+func Summarize(ctx context.Context, client Summarizer, text string) (string, error) {
+	summary, err := client.Summarization(ctx, &hf.SummarizationRequest{
+		Inputs: []string{text},
+	})
+	if err != nil {
+		return "", fmt.Errorf("summarization request failed: %w", err)
+	}
+
+	// API bisa mengembalikan slice kosong untuk model atau error tertentu;
+	// jangan mengakses summary[0] tanpa memeriksa panjangnya
+	if len(summary) == 0 {
+		return "", ErrNoSummary
+	}
+	return summary[0].SummaryText, nil
+}
+
+// TextClassifier adalah bagian dari *hf.InferenceClient yang dibutuhkan ClassifyText.
+type TextClassifier interface {
+	TextClassification(ctx context.Context, req *hf.TextClassificationRequest) (hf.TextClassificationResponse, error)
+}
+
+// Classification adalah satu label hasil klasifikasi teks beserta skornya.
+type Classification struct {
+	Label string  `json:"label"`
+	Score float32 `json:"score"`
+}
+
+// Classifications adalah hasil ClassifyText, terurut dari skor tertinggi.
+type Classifications []Classification
+
+// String menampilkan setiap label dan skornya di baris terpisah.
+func (c Classifications) String() string {
+	lines := make([]string, len(c))
+	for i, class := range c {
+		lines[i] = fmt.Sprintf("%s: %.4f", class.Label, class.Score)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ClassifyText mengklasifikasikan text (misal sentimen atau intent) dengan
+// endpoint text-classification dan mengembalikan pasangan label/skor yang
+// diurutkan dari skor tertinggi.
+func ClassifyText(ctx context.Context, client TextClassifier, text string) (Classifications, error) {
+	resp, err := client.TextClassification(ctx, &hf.TextClassificationRequest{
+		Inputs: text,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("text classification request failed: %w", err)
+	}
+
+	// Satu input menghasilkan satu daftar label di elemen pertama
+	if len(resp) == 0 || len(resp[0]) == 0 {
+		return nil, ErrNoClassification
+	}
+	result := make(Classifications, 0, len(resp[0]))
+	for _, class := range resp[0] {
+		result = append(result, Classification{Label: class.Label, Score: class.Score})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+	return result, nil
+}
+
+// QuestionAnswerer adalah bagian dari *hf.InferenceClient yang dibutuhkan AnswerQuestion.
+type QuestionAnswerer interface {
+	QuestionAnswering(ctx context.Context, req *hf.QuestionAnsweringRequest) (*hf.QuestionAnsweringResponse, error)
+}
+
+// QAInputs adalah pertanyaan beserta paragraf teks yang berisi jawabannya.
+type QAInputs struct {
+	Question string
+	Context  string
+}
+
+// QAAnswer adalah potongan Context yang menjawab pertanyaan. Start dan End
+// adalah offset karakter (bukan byte) potongan tersebut di Context seperti
+// yang dikirim API, sehingga untuk teks non-ASCII potongannya adalah
+// []rune(Context)[Start:End].
+type QAAnswer struct {
+	Answer string  `json:"answer"`
+	Score  float64 `json:"score"`
+	Start  int     `json:"start"`
+	End    int     `json:"end"`
+}
+
+// String menampilkan jawaban beserta skornya.
+func (a QAAnswer) String() string {
+	return fmt.Sprintf("%s (score %.4f)", a.Answer, a.Score)
+}
+
+// AnswerQuestion mencari jawaban inputs.Question di dalam inputs.Context
+// dengan endpoint question-answering dan DefaultQAModel. Berbeda dengan
+// ConnectAIModel, jawabannya selalu berupa potongan teks dari Context.
+func AnswerQuestion(ctx context.Context, client QuestionAnswerer, inputs QAInputs) (QAAnswer, error) {
+	if strings.TrimSpace(inputs.Question) == "" {
+		return QAAnswer{}, ErrEmptyQuery
+	}
+	if strings.TrimSpace(inputs.Context) == "" {
+		return QAAnswer{}, ErrEmptyContext
+	}
+
+	resp, err := client.QuestionAnswering(ctx, &hf.QuestionAnsweringRequest{
+		Inputs: hf.QuestionAnsweringInputs{Question: inputs.Question, Context: inputs.Context},
+		Model:  DefaultQAModel,
+	})
+	if err != nil {
+		return QAAnswer{}, fmt.Errorf("question answering request failed: %w", err)
+	}
+	if resp == nil {
+		return QAAnswer{}, ErrNoAnswer
+	}
+	return QAAnswer{Answer: resp.Answer, Score: resp.Score, Start: resp.Start, End: resp.End}, nil
+}
+
+// Translator adalah bagian dari *hf.InferenceClient yang dibutuhkan Translate.
+type Translator interface {
+	Translation(ctx context.Context, req *hf.TranslationRequest) (hf.TranslationResponse, error)
+}
+
+// TranslationModel mengembalikan ID model Helsinki-NLP opus-mt untuk
+// menerjemahkan dari srcLang ke tgtLang, misal "Helsinki-NLP/opus-mt-en-id"
+// untuk "en" dan "id".
+func TranslationModel(srcLang, tgtLang string) string {
+	return fmt.Sprintf("Helsinki-NLP/opus-mt-%s-%s", srcLang, tgtLang)
+}
+
+// Translate menerjemahkan text dari srcLang ke tgtLang (kode bahasa ISO 639-1,
+// misal "en" dan "id") dengan endpoint translation dan model dari
+// TranslationModel.
+func Translate(ctx context.Context, client Translator, text, srcLang, tgtLang string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return "", ErrEmptyQuery
+	}
+	if srcLang == "" || tgtLang == "" {
+		return "", fmt.Errorf("source and target languages are required, got %q and %q", srcLang, tgtLang)
+	}
+
+	resp, err := client.Translation(ctx, &hf.TranslationRequest{
+		Inputs: []string{text},
+		Model:  TranslationModel(srcLang, tgtLang),
+	})
+	if err != nil {
+		return "", fmt.Errorf("translation request failed: %w", err)
+	}
+	if len(resp) == 0 || resp[0].TranslationText == "" {
+		return "", ErrNoTranslation
+	}
+	return resp[0].TranslationText, nil
+}
@@ -0,0 +1,219 @@
+package tableqa_test
+
+import (
+	"context"
+	"errors"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	hf "github.com/hupe1980/go-huggingface"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeSummarizer adalah Summarizer tiruan yang mencatat request terakhir.
+type fakeSummarizer struct {
+	response hf.SummarizationResponse
+	err      error
+	request  *hf.SummarizationRequest
+}
+
+func (f *fakeSummarizer) Summarization(ctx context.Context, req *hf.SummarizationRequest) (hf.SummarizationResponse, error) {
+	f.request = req
+	return f.response, f.err
+}
+
+// fakeClassifier adalah TextClassifier tiruan dengan respons tetap.
+type fakeClassifier struct {
+	response hf.TextClassificationResponse
+	err      error
+}
+
+func (f *fakeClassifier) TextClassification(ctx context.Context, req *hf.TextClassificationRequest) (hf.TextClassificationResponse, error) {
+	return f.response, f.err
+}
+
+// fakeQuestionAnswerer adalah QuestionAnswerer tiruan yang mencatat request terakhir.
+type fakeQuestionAnswerer struct {
+	response *hf.QuestionAnsweringResponse
+	err      error
+	request  *hf.QuestionAnsweringRequest
+}
+
+func (f *fakeQuestionAnswerer) QuestionAnswering(ctx context.Context, req *hf.QuestionAnsweringRequest) (*hf.QuestionAnsweringResponse, error) {
+	f.request = req
+	return f.response, f.err
+}
+
+// fakeTranslator adalah Translator tiruan yang mencatat request terakhir.
+type fakeTranslator struct {
+	response hf.TranslationResponse
+	err      error
+	request  *hf.TranslationRequest
+}
+
+func (f *fakeTranslator) Translation(ctx context.Context, req *hf.TranslationRequest) (hf.TranslationResponse, error) {
+	f.request = req
+	return f.response, f.err
+}
+
+var _ = Describe("Hugging Face helpers", func() {
+	Describe("summarize", func() {
+		It("returns the first summary text", func() {
+			client := &fakeSummarizer{response: hf.SummarizationResponse{
+				{SummaryText: "The refrigerator uses the most energy."},
+			}}
+
+			summary, err := tableqa.Summarize(context.Background(), client, "some long text")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(summary).Should(Equal("The refrigerator uses the most energy."))
+			Expect(client.request.Inputs).Should(Equal([]string{"some long text"}))
+		})
+
+		It("returns ErrNoSummary instead of panicking on an empty response", func() {
+			client := &fakeSummarizer{response: hf.SummarizationResponse{}}
+
+			var err error
+			Expect(func() {
+				_, err = tableqa.Summarize(context.Background(), client, "some long text")
+			}).ShouldNot(Panic())
+			Expect(err).Should(MatchError(tableqa.ErrNoSummary))
+		})
+
+		It("wraps the client error", func() {
+			clientErr := errors.New("huggingfaces error: rate limited")
+			client := &fakeSummarizer{err: clientErr}
+
+			_, err := tableqa.Summarize(context.Background(), client, "some long text")
+			Expect(errors.Is(err, clientErr)).Should(BeTrue())
+			Expect(err).Should(MatchError("summarization request failed: huggingfaces error: rate limited"))
+		})
+	})
+
+	Describe("classifyText", func() {
+		It("returns the labels sorted by descending score", func() {
+			response := hf.TextClassificationResponse{{
+				{Label: "NEGATIVE", Score: 0.1},
+				{Label: "POSITIVE", Score: 0.9},
+			}}
+			client := &fakeClassifier{response: response}
+
+			labels, err := tableqa.ClassifyText(context.Background(), client, "I love how little energy the TV uses")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(labels).Should(Equal(tableqa.Classifications{
+				{Label: "POSITIVE", Score: 0.9},
+				{Label: "NEGATIVE", Score: 0.1},
+			}))
+			Expect(labels.String()).Should(Equal("POSITIVE: 0.9000\nNEGATIVE: 0.1000"))
+		})
+
+		It("returns ErrNoClassification when no labels come back", func() {
+			client := &fakeClassifier{response: hf.TextClassificationResponse{}}
+
+			_, err := tableqa.ClassifyText(context.Background(), client, "text")
+			Expect(err).Should(MatchError(tableqa.ErrNoClassification))
+		})
+
+		It("wraps the client error", func() {
+			clientErr := errors.New("huggingfaces error: model not found")
+			client := &fakeClassifier{err: clientErr}
+
+			_, err := tableqa.ClassifyText(context.Background(), client, "text")
+			Expect(errors.Is(err, clientErr)).Should(BeTrue())
+		})
+	})
+
+	Describe("answerQuestion", func() {
+		const paragraph = "The refrigerator in the kitchen uses 1.2 kWh per hour."
+
+		It("returns the answer span and score", func() {
+			client := &fakeQuestionAnswerer{response: &hf.QuestionAnsweringResponse{
+				Answer: "1.2 kWh", Score: 0.93, Start: 37, End: 44,
+			}}
+
+			answer, err := tableqa.AnswerQuestion(context.Background(), client, tableqa.QAInputs{
+				Question: "How much does the refrigerator use?",
+				Context:  paragraph,
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(answer).Should(Equal(tableqa.QAAnswer{Answer: "1.2 kWh", Score: 0.93, Start: 37, End: 44}))
+			Expect(paragraph[answer.Start:answer.End]).Should(Equal(answer.Answer))
+			Expect(answer.String()).Should(Equal("1.2 kWh (score 0.9300)"))
+
+			Expect(client.request.Model).Should(Equal(tableqa.DefaultQAModel))
+			Expect(client.request.Inputs).Should(Equal(hf.QuestionAnsweringInputs{
+				Question: "How much does the refrigerator use?",
+				Context:  paragraph,
+			}))
+		})
+
+		DescribeTable("rejects missing inputs without calling the API",
+			func(inputs tableqa.QAInputs, want error) {
+				client := &fakeQuestionAnswerer{}
+				_, err := tableqa.AnswerQuestion(context.Background(), client, inputs)
+				Expect(err).Should(MatchError(want))
+				Expect(client.request).Should(BeNil())
+			},
+			Entry("empty question", tableqa.QAInputs{Question: " ", Context: paragraph}, tableqa.ErrEmptyQuery),
+			Entry("empty context", tableqa.QAInputs{Question: "How much?"}, tableqa.ErrEmptyContext),
+		)
+
+		It("returns ErrNoAnswer when the API sends no answer", func() {
+			_, err := tableqa.AnswerQuestion(context.Background(), &fakeQuestionAnswerer{}, tableqa.QAInputs{Question: "How much?", Context: paragraph})
+			Expect(errors.Is(err, tableqa.ErrNoAnswer)).Should(BeTrue())
+		})
+
+		It("wraps the client error", func() {
+			clientErr := errors.New("huggingfaces error: model is loading")
+			client := &fakeQuestionAnswerer{err: clientErr}
+
+			_, err := tableqa.AnswerQuestion(context.Background(), client, tableqa.QAInputs{Question: "How much?", Context: paragraph})
+			Expect(errors.Is(err, clientErr)).Should(BeTrue())
+		})
+	})
+
+	Describe("translate", func() {
+		It("returns the translated text from the language pair's model", func() {
+			client := &fakeTranslator{response: hf.TranslationResponse{
+				{TranslationText: "Kulkas menggunakan energi paling banyak."},
+			}}
+
+			text, err := tableqa.Translate(context.Background(), client, "The refrigerator uses the most energy.", "en", "id")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(text).Should(Equal("Kulkas menggunakan energi paling banyak."))
+			Expect(client.request.Model).Should(Equal("Helsinki-NLP/opus-mt-en-id"))
+			Expect(client.request.Inputs).Should(Equal([]string{"The refrigerator uses the most energy."}))
+		})
+
+		DescribeTable("returns ErrNoTranslation on empty output",
+			func(response hf.TranslationResponse) {
+				client := &fakeTranslator{response: response}
+				_, err := tableqa.Translate(context.Background(), client, "Hello", "en", "id")
+				Expect(err).Should(MatchError(tableqa.ErrNoTranslation))
+			},
+			Entry("no results", hf.TranslationResponse{}),
+			Entry("empty text", hf.TranslationResponse{{TranslationText: ""}}),
+		)
+
+		DescribeTable("rejects missing inputs without calling the API",
+			func(text, src, tgt string) {
+				client := &fakeTranslator{}
+				_, err := tableqa.Translate(context.Background(), client, text, src, tgt)
+				Expect(err).Should(HaveOccurred())
+				Expect(client.request).Should(BeNil())
+			},
+			Entry("empty text", " ", "en", "id"),
+			Entry("missing source language", "Hello", "", "id"),
+			Entry("missing target language", "Hello", "en", ""),
+		)
+
+		It("wraps the client error", func() {
+			clientErr := errors.New("huggingfaces error: model not found")
+			client := &fakeTranslator{err: clientErr}
+
+			_, err := tableqa.Translate(context.Background(), client, "Hello", "en", "xx")
+			Expect(errors.Is(err, clientErr)).Should(BeTrue())
+			Expect(err).Should(MatchError("translation request failed: huggingfaces error: model not found"))
+		})
+	})
+})
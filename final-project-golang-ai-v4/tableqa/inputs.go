@@ -0,0 +1,87 @@
+package tableqa
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Inputs adalah payload yang dikirim ke model table-question-answering.
+// Table memetakan nama kolom ke nilai-nilainya dan Query berisi pertanyaan.
+type Inputs struct {
+	Table map[string][]string `json:"table"`
+	Query string              `json:"query"`
+}
+
+// Error yang dikembalikan oleh Inputs.Validate.
+var (
+	ErrEmptyQuery  = errors.New("query is empty")
+	ErrEmptyTable  = errors.New("table is empty")
+	ErrRaggedTable = errors.New("table columns have unequal lengths")
+)
+
+// Validate memastikan Inputs layak dikirim ke model: query tidak kosong,
+// tabel memiliki setidaknya satu kolom, dan semua kolom sama panjang.
+func (i Inputs) Validate() error {
+	if strings.TrimSpace(i.Query) == "" {
+		return ErrEmptyQuery
+	}
+	if len(i.Table) == 0 {
+		return ErrEmptyTable
+	}
+
+	// Semua kolom harus memiliki jumlah baris yang sama
+	return checkColumnsAligned(i.Table)
+}
+
+// checkColumnsAligned memastikan semua kolom di table sama panjang. Panjang
+// yang diharapkan adalah panjang yang paling banyak dimiliki kolom, sehingga
+// kolom yang disebut di *ColumnLengthError adalah kolom yang menyimpang.
+// Kolom diperiksa sesuai urutan nama agar hasilnya selalu sama.
+func checkColumnsAligned(table map[string][]string) error {
+	columns := make([]string, 0, len(table))
+	counts := make(map[int]int)
+	for column, values := range table {
+		columns = append(columns, column)
+		counts[len(values)]++
+	}
+	if len(counts) <= 1 {
+		return nil
+	}
+	sort.Strings(columns)
+
+	// Cari panjang yang paling umum; jika seri, pakai panjang kolom pertama
+	expected := len(table[columns[0]])
+	for rows, n := range counts {
+		if n > counts[expected] {
+			expected = rows
+		}
+	}
+
+	for _, column := range columns {
+		if rows := len(table[column]); rows != expected {
+			return &ColumnLengthError{Column: column, Rows: rows, Expected: expected}
+		}
+	}
+	return nil
+}
+
+// TruncateRows mengembalikan salinan table yang hanya berisi maxRows baris
+// pertama di setiap kolom, beserta penanda apakah ada baris yang dibuang.
+// maxRows <= 0 berarti tanpa batas. Map asli tidak diubah.
+func TruncateRows(table map[string][]string, maxRows int) (map[string][]string, bool) {
+	if maxRows <= 0 {
+		return table, false
+	}
+
+	truncated := false
+	result := make(map[string][]string, len(table))
+	for column, values := range table {
+		if len(values) > maxRows {
+			values = values[:maxRows]
+			truncated = true
+		}
+		result[column] = values
+	}
+	return result, truncated
+}
@@ -0,0 +1,146 @@
+package tableqa_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Inputs", func() {
+	Describe("inputs.Validate", func() {
+		DescribeTable("rejects invalid inputs",
+			func(inputs tableqa.Inputs, expected error) {
+				Expect(inputs.Validate()).Should(MatchError(expected))
+			},
+			Entry("empty query", tableqa.Inputs{
+				Table: map[string][]string{"Name": {"John"}},
+				Query: "  ",
+			}, tableqa.ErrEmptyQuery),
+			Entry("empty table", tableqa.Inputs{
+				Table: map[string][]string{},
+				Query: "Who is John?",
+			}, tableqa.ErrEmptyTable),
+			Entry("ragged table", tableqa.Inputs{
+				Table: map[string][]string{"Name": {"John", "Doe"}, "Age": {"30"}},
+				Query: "What is the age of Doe?",
+			}, tableqa.ErrRaggedTable),
+		)
+
+		DescribeTable("names the column whose length differs",
+			func(table map[string][]string, expected tableqa.ColumnLengthError) {
+				err := tableqa.Inputs{Table: table, Query: "How many rows?"}.Validate()
+
+				var lengthErr *tableqa.ColumnLengthError
+				Expect(errors.As(err, &lengthErr)).Should(BeTrue())
+				Expect(*lengthErr).Should(Equal(expected))
+				Expect(err).Should(MatchError(tableqa.ErrRaggedTable))
+			},
+			Entry("a short column",
+				map[string][]string{"Name": {"John", "Jane", "Doe"}, "Age": {"30", "25"}, "City": {"A", "B", "C"}},
+				tableqa.ColumnLengthError{Column: "Age", Rows: 2, Expected: 3}),
+			Entry("a long column",
+				map[string][]string{"Name": {"John"}, "Age": {"30"}, "City": {"A", "B"}},
+				tableqa.ColumnLengthError{Column: "City", Rows: 2, Expected: 1}),
+			Entry("an empty column",
+				map[string][]string{"Name": {"John", "Jane"}, "Age": {"30", "25"}, "Notes": {}},
+				tableqa.ColumnLengthError{Column: "Notes", Rows: 0, Expected: 2}),
+			Entry("a tie, measured against the first column by name",
+				map[string][]string{"Name": {"John", "Doe"}, "Age": {"30"}},
+				tableqa.ColumnLengthError{Column: "Name", Rows: 2, Expected: 1}),
+		)
+
+		It("describes the offending column in the message", func() {
+			err := tableqa.Inputs{
+				Table: map[string][]string{"Name": {"John", "Jane"}, "Age": {"30", "25"}, "City": {"A"}},
+				Query: "Who lives in A?",
+			}.Validate()
+			Expect(err).Should(MatchError(`table columns have unequal lengths: column "City" has 1 rows, expected 2`))
+		})
+
+		It("accepts a well-formed input", func() {
+			inputs := tableqa.Inputs{
+				Table: map[string][]string{"Name": {"John", "Doe"}, "Age": {"30", "40"}},
+				Query: "What is the age of John?",
+			}
+			Expect(inputs.Validate()).Should(Succeed())
+		})
+
+		It("is checked before any request is sent", func() {
+			mockClient := &MockClient{
+				MockRoundTrip: func(req *http.Request) (*http.Response, error) {
+					Fail("no request should be sent for invalid inputs")
+					return nil, nil
+				},
+			}
+			connector := &tableqa.AIModelConnector{
+				Client: &http.Client{Transport: mockClient},
+			}
+
+			_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{Query: "Who is John?"}, "token")
+			Expect(err).Should(MatchError(tableqa.ErrEmptyTable))
+		})
+	})
+
+	Describe("truncateRows", func() {
+		table := map[string][]string{
+			"Name": {"John", "Doe", "Jane"},
+			"Age":  {"30", "40", "25"},
+		}
+
+		It("keeps the table unchanged at or below the limit", func() {
+			result, truncated := tableqa.TruncateRows(table, 3)
+			Expect(truncated).Should(BeFalse())
+			Expect(result).Should(Equal(table))
+
+			result, truncated = tableqa.TruncateRows(table, 0)
+			Expect(truncated).Should(BeFalse())
+			Expect(result).Should(Equal(table))
+		})
+
+		It("keeps the first rows of every column when over the limit", func() {
+			result, truncated := tableqa.TruncateRows(table, 2)
+			Expect(truncated).Should(BeTrue())
+			Expect(result).Should(Equal(map[string][]string{
+				"Name": {"John", "Doe"},
+				"Age":  {"30", "40"},
+			}))
+			Expect(tableqa.Inputs{Table: result, Query: "Who is 40?"}.Validate()).Should(Succeed())
+			Expect(table["Name"]).Should(HaveLen(3))
+		})
+
+		It("sends only MaxRows rows to the model", func() {
+			var sent tableqa.Inputs
+			var logs bytes.Buffer
+			connector := tableqa.NewAIModelConnector(
+				tableqa.WithMaxRows(1),
+				tableqa.WithLogger(log.New(&logs, "", 0)),
+				tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+					Expect(json.NewDecoder(req.Body).Decode(&sent)).Should(Succeed())
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       ioutil.NopCloser(strings.NewReader(`{"answer": "John"}`)),
+					}, nil
+				})),
+			)
+
+			_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{Table: table, Query: "Who is first?"}, "token")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(sent.Table).Should(Equal(map[string][]string{"Name": {"John"}, "Age": {"30"}}))
+			Expect(logs.String()).Should(ContainSubstring("truncated to the first 1 rows"))
+
+			_, metrics, err := connector.ConnectAIModelWithMetrics(context.Background(), tableqa.Inputs{Table: table, Query: "Who is last?"}, "token")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(metrics.TruncatedRows).Should(Equal(2))
+		})
+	})
+})
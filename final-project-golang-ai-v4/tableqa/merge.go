@@ -0,0 +1,89 @@
+package tableqa
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrSchemaMismatch dikembalikan oleh MergeTables ketika tabel yang digabung
+// tidak memiliki header yang sama.
+var ErrSchemaMismatch = errors.New("tables have different columns")
+
+// MergeTables menggabungkan baris-baris dari tables yang memiliki kolom yang
+// sama menjadi satu tabel, sesuai urutan tables. Tabel dengan kolom berbeda
+// dikembalikan sebagai error yang membungkus ErrSchemaMismatch. Tabel masukan
+// tidak diubah.
+func MergeTables(tables ...map[string][]string) (map[string][]string, error) {
+	merged := make(map[string][]string)
+	if len(tables) == 0 {
+		return merged, nil
+	}
+
+	want := sortedKeys(tables[0])
+	for i, table := range tables {
+		if got := sortedKeys(table); !equalStrings(got, want) {
+			return nil, fmt.Errorf("%w: table %d has columns [%s], want [%s]",
+				ErrSchemaMismatch, i+1, strings.Join(got, ", "), strings.Join(want, ", "))
+		}
+		for column, values := range table {
+			merged[column] = append(merged[column], values...)
+		}
+	}
+
+	// Kolom tanpa baris tetap berupa slice kosong, bukan nil, seperti hasil CsvToSlice
+	for _, column := range want {
+		if merged[column] == nil {
+			merged[column] = []string{}
+		}
+	}
+	return merged, nil
+}
+
+// MergeTableList sama dengan MergeTables untuk Table. Urutan kolom setiap
+// tabel juga harus sama, dan menjadi urutan kolom hasil.
+func MergeTableList(tables ...Table) (Table, error) {
+	if len(tables) == 0 {
+		return Table{Data: map[string][]string{}}, nil
+	}
+
+	data := make([]map[string][]string, len(tables))
+	for i, t := range tables {
+		if !equalStrings(t.Columns, tables[0].Columns) {
+			return Table{}, fmt.Errorf("%w: table %d has columns [%s], want [%s]",
+				ErrSchemaMismatch, i+1, strings.Join(t.Columns, ", "), strings.Join(tables[0].Columns, ", "))
+		}
+		data[i] = t.Data
+	}
+
+	merged, err := MergeTables(data...)
+	if err != nil {
+		return Table{}, err
+	}
+	columns := append([]string(nil), tables[0].Columns...)
+	return Table{Columns: columns, Data: merged}, nil
+}
+
+// sortedKeys mengembalikan nama kolom table secara urut.
+func sortedKeys(table map[string][]string) []string {
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// equalStrings melaporkan apakah a dan b berisi string yang sama dengan urutan yang sama.
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package tableqa_test
+
+import (
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("mergeTables", func() {
+	january := map[string][]string{"Date": {"2022-01-01", "2022-01-02"}, "Energy": {"1.2", "0.8"}}
+	february := map[string][]string{"Energy": {"1.5"}, "Date": {"2022-02-01"}}
+
+	It("concatenates the rows of tables with the same columns", func() {
+		merged, err := tableqa.MergeTables(january, february)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(merged).Should(Equal(map[string][]string{
+			"Date":   {"2022-01-01", "2022-01-02", "2022-02-01"},
+			"Energy": {"1.2", "0.8", "1.5"},
+		}))
+	})
+
+	It("does not modify the input tables", func() {
+		_, err := tableqa.MergeTables(january, february)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(january["Date"]).Should(HaveLen(2))
+	})
+
+	It("rejects tables with different columns", func() {
+		_, err := tableqa.MergeTables(january, map[string][]string{"Date": {"2022-03-01"}, "Room": {"Kitchen"}})
+		Expect(err).Should(MatchError(tableqa.ErrSchemaMismatch))
+		Expect(err).Should(MatchError(ContainSubstring("table 2 has columns [Date, Room], want [Date, Energy]")))
+	})
+
+	It("returns an empty table for no input", func() {
+		merged, err := tableqa.MergeTables()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(merged).Should(BeEmpty())
+	})
+
+	Describe("mergeTableList", func() {
+		It("keeps the column order of the first table", func() {
+			a, err := tableqa.CsvToTable("Date,Energy\n2022-01-01,1.2")
+			Expect(err).ShouldNot(HaveOccurred())
+			b, err := tableqa.CsvToTable("Date,Energy\n2022-02-01,1.5")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			merged, err := tableqa.MergeTableList(a, b)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(merged.Columns).Should(Equal([]string{"Date", "Energy"}))
+			Expect(merged.Data["Energy"]).Should(Equal([]string{"1.2", "1.5"}))
+		})
+
+		It("rejects the same columns in a different order", func() {
+			a, err := tableqa.CsvToTable("Date,Energy\n2022-01-01,1.2")
+			Expect(err).ShouldNot(HaveOccurred())
+			b, err := tableqa.CsvToTable("Energy,Date\n1.5,2022-02-01")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, err = tableqa.MergeTableList(a, b)
+			Expect(err).Should(MatchError(tableqa.ErrSchemaMismatch))
+		})
+
+		It("keeps empty columns from header-only files", func() {
+			a, err := tableqa.CsvToTable("Date,Energy")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			merged, err := tableqa.MergeTableList(a, a)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(merged.Data).Should(Equal(map[string][]string{"Date": {}, "Energy": {}}))
+		})
+
+		It("passes CheckTableData when only another file has rows", func() {
+			empty, err := tableqa.CsvToTable("Date,Energy")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(tableqa.CheckTableData(empty)).Should(MatchError(tableqa.ErrNoData))
+			rows, err := tableqa.CsvToTable("Date,Energy\n2022-02-01,1.5")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			merged, err := tableqa.MergeTableList(empty, rows)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(tableqa.CheckTableData(merged)).Should(Succeed())
+		})
+	})
+})
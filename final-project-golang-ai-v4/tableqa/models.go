@@ -0,0 +1,43 @@
+package tableqa
+
+// Task yang dipakai di ModelInfo.Task, sesuai nama pipeline di Hugging Face Hub.
+const (
+	TaskTableQA            = "table-question-answering"
+	TaskSummarization      = "summarization"
+	TaskTextClassification = "text-classification"
+	TaskQuestionAnswering  = "question-answering"
+	TaskTranslation        = "translation"
+)
+
+// ModelInfo menjelaskan satu model yang didukung oleh package ini.
+type ModelInfo struct {
+	// ID adalah ID model di Hugging Face Hub
+	ID string `json:"id"`
+	// Task adalah jenis pipeline model, misal TaskTableQA
+	Task string `json:"task"`
+	// Description adalah penjelasan singkat satu baris
+	Description string `json:"description"`
+}
+
+// supportedModels adalah daftar model yang sudah dicoba dengan package ini.
+// Tambahkan model baru di sini setelah memastikan format responsnya cocok.
+var supportedModels = []ModelInfo{
+	{ID: DefaultModel, Task: TaskTableQA, Description: "TAPAS base fine-tuned on WikiTableQuestions (default)"},
+	{ID: "google/tapas-large-finetuned-wtq", Task: TaskTableQA, Description: "TAPAS large fine-tuned on WikiTableQuestions, more accurate but slower"},
+	{ID: "google/tapas-base-finetuned-sqa", Task: TaskTableQA, Description: "TAPAS base fine-tuned on SQA, for simple lookup questions"},
+	{ID: "google/tapas-mini-finetuned-wtq", Task: TaskTableQA, Description: "TAPAS mini fine-tuned on WikiTableQuestions, fast but less accurate"},
+	{ID: "facebook/bart-large-cnn", Task: TaskSummarization, Description: "BART large fine-tuned on CNN/Daily Mail news summaries"},
+	{ID: "sshleifer/distilbart-cnn-12-6", Task: TaskSummarization, Description: "Distilled BART for faster summarization"},
+	{ID: "distilbert-base-uncased-finetuned-sst-2-english", Task: TaskTextClassification, Description: "DistilBERT sentiment classifier (POSITIVE/NEGATIVE)"},
+	{ID: DefaultQAModel, Task: TaskQuestionAnswering, Description: "RoBERTa base fine-tuned on SQuAD 2.0, answers with a span of the context"},
+	{ID: "Helsinki-NLP/opus-mt-en-id", Task: TaskTranslation, Description: "MarianMT English to Indonesian translation"},
+	{ID: "Helsinki-NLP/opus-mt-id-en", Task: TaskTranslation, Description: "MarianMT Indonesian to English translation"},
+}
+
+// SupportedModels mengembalikan daftar model yang didukung, dikelompokkan per
+// task. Slice yang dikembalikan adalah salinan sehingga aman diubah.
+func SupportedModels() []ModelInfo {
+	models := make([]ModelInfo, len(supportedModels))
+	copy(models, supportedModels)
+	return models
+}
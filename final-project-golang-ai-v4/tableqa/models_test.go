@@ -0,0 +1,35 @@
+package tableqa_test
+
+import (
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("supportedModels", func() {
+	It("lists models that each have an ID, a task and a description", func() {
+		models := tableqa.SupportedModels()
+		Expect(models).ShouldNot(BeEmpty())
+		for _, m := range models {
+			Expect(m.ID).ShouldNot(BeEmpty())
+			Expect(m.Task).Should(BeElementOf(tableqa.TaskTableQA, tableqa.TaskSummarization, tableqa.TaskTextClassification, tableqa.TaskQuestionAnswering, tableqa.TaskTranslation), m.ID)
+			Expect(m.Description).ShouldNot(BeEmpty(), m.ID)
+		}
+	})
+
+	It("includes the default model as a table QA model", func() {
+		Expect(tableqa.SupportedModels()).Should(ContainElement(HaveField("ID", tableqa.DefaultModel)))
+		for _, m := range tableqa.SupportedModels() {
+			if m.ID == tableqa.DefaultModel {
+				Expect(m.Task).Should(Equal(tableqa.TaskTableQA))
+			}
+		}
+	})
+
+	It("returns a copy", func() {
+		models := tableqa.SupportedModels()
+		models[0].ID = "changed"
+		Expect(tableqa.SupportedModels()[0].ID).Should(Equal(tableqa.DefaultModel))
+	})
+})
@@ -0,0 +1,99 @@
+package tableqa
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// currencySymbols adalah simbol dan kode mata uang yang dibuang dari awal
+// atau akhir sel oleh NormalizeNumbers. Kode yang lebih panjang ditulis lebih
+// dulu agar "IDR" tidak terpotong menjadi "ID" + "R".
+var currencySymbols = []string{"IDR", "USD", "EUR", "Rp", "$", "€", "£", "¥", "₹"}
+
+// NormalizeNumbers mengembalikan salinan table dengan angka berformat, misal
+// "$1,234.00", "Rp 1.234.567" atau "1 234,56", diubah menjadi angka polos
+// seperti "1234.00", "1234567" dan "1234.56" yang mudah dihitung TAPAS.
+// Kolom hanya diubah jika setiap selnya yang tidak kosong bisa dinormalisasi
+// dan hasilnya dianggap angka oleh InferColumnTypes; kolom lain disalin apa
+// adanya. Map asli tidak diubah sehingga nilai aslinya tetap bisa ditampilkan.
+func NormalizeNumbers(table map[string][]string) map[string][]string {
+	result := make(map[string][]string, len(table))
+	for column, values := range table {
+		result[column] = values
+		if IsNumericType(inferType(values)) {
+			continue
+		}
+
+		normalized := make([]string, len(values))
+		ok := true
+		for i, v := range values {
+			if strings.TrimSpace(v) == "" {
+				continue
+			}
+			if normalized[i], ok = normalizeNumber(v); !ok {
+				break
+			}
+		}
+		if ok && IsNumericType(inferType(normalized)) {
+			result[column] = normalized
+		}
+	}
+	return result
+}
+
+// normalizeNumber membuang simbol mata uang dan pemisah ribuan dari s lalu
+// mengganti koma desimal dengan titik. Jika s memuat titik dan koma, yang
+// terakhir dianggap pemisah desimal. Satu koma yang diikuti tepat tiga digit
+// ("1,234") dianggap pemisah ribuan; koma lain dianggap desimal ("1,5").
+// Titik yang muncul lebih dari sekali ("1.234.567") dianggap pemisah ribuan.
+func normalizeNumber(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimSpace(strings.TrimPrefix(s, "-"))
+	for _, symbol := range currencySymbols {
+		s = strings.TrimSpace(strings.TrimPrefix(s, symbol))
+		s = strings.TrimSpace(strings.TrimSuffix(s, symbol))
+	}
+	if !negative {
+		negative = strings.HasPrefix(s, "-")
+		s = strings.TrimPrefix(s, "-")
+	}
+
+	// Spasi (termasuk spasi tak terputus) di tengah angka adalah pemisah ribuan
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+
+	dots, commas := strings.Count(s, "."), strings.Count(s, ",")
+	switch {
+	case dots > 0 && commas > 0:
+		if strings.LastIndex(s, ",") > strings.LastIndex(s, ".") {
+			s = strings.Replace(strings.ReplaceAll(s, ".", ""), ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case commas == 1 && len(s)-strings.Index(s, ",")-1 != 3:
+		s = strings.Replace(s, ",", ".", 1)
+	case commas > 0:
+		s = strings.ReplaceAll(s, ",", "")
+	case dots > 1:
+		s = strings.ReplaceAll(s, ".", "")
+	}
+
+	// Hanya digit dan satu titik desimal yang tersisa; "inf", "1e3" dan
+	// teks lain yang diterima strconv.ParseFloat ditolak
+	if s == "" || strings.Trim(s, "0123456789.") != "" {
+		return "", false
+	}
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return "", false
+	}
+	if negative {
+		s = "-" + s
+	}
+	return s, true
+}
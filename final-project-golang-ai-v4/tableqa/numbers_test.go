@@ -0,0 +1,51 @@
+package tableqa_test
+
+import (
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("normalizeNumbers", func() {
+	DescribeTable("cleans formatted numeric columns",
+		func(values, expected []string) {
+			normalized := tableqa.NormalizeNumbers(map[string][]string{"Price": values})
+			Expect(normalized["Price"]).Should(Equal(expected))
+		},
+		Entry("dollar amounts", []string{"$1,234.00", "$5.50", "$12,000"}, []string{"1234.00", "5.50", "12000"}),
+		Entry("euro amounts", []string{"1.234,56 €", "€ 7,5"}, []string{"1234.56", "7.5"}),
+		Entry("space thousands separators", []string{"1 234,56", "12 000"}, []string{"1234.56", "12000"}),
+		Entry("non-breaking space separators", []string{"1\u00a0234,56"}, []string{"1234.56"}),
+		Entry("rupiah", []string{"Rp 1.234.567", "Rp1.500.000,50"}, []string{"1234567", "1500000.50"}),
+		Entry("currency codes", []string{"USD 1,200", "2,500.75 USD"}, []string{"1200", "2500.75"}),
+		Entry("negative amounts", []string{"-$1,234.00", "$-5"}, []string{"-1234.00", "-5"}),
+		Entry("blank cells", []string{"$1,000", "", "$2,000"}, []string{"1000", "", "2000"}),
+	)
+
+	DescribeTable("leaves other columns unchanged",
+		func(values []string) {
+			normalized := tableqa.NormalizeNumbers(map[string][]string{"Column": values})
+			Expect(normalized["Column"]).Should(Equal(values))
+		},
+		Entry("plain numbers", []string{"1.2", "0.8", "1e3"}),
+		Entry("text", []string{"Refrigerator", "TV"}),
+		Entry("numbers mixed with text", []string{"$1,000", "unknown"}),
+		Entry("infinity", []string{"$inf"}),
+		Entry("an all-blank column", []string{"", ""}),
+	)
+
+	It("does not modify the original table", func() {
+		table := map[string][]string{
+			"Appliance": {"Refrigerator", "TV"},
+			"Cost":      {"$1,200.00", "$850.00"},
+		}
+		normalized := tableqa.NormalizeNumbers(table)
+
+		Expect(normalized).Should(Equal(map[string][]string{
+			"Appliance": {"Refrigerator", "TV"},
+			"Cost":      {"1200.00", "850.00"},
+		}))
+		Expect(table["Cost"]).Should(Equal([]string{"$1,200.00", "$850.00"}))
+	})
+})
@@ -0,0 +1,131 @@
+package tableqa
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// PayloadFormat menentukan bentuk tabel di body JSON yang dikirim ke model.
+type PayloadFormat string
+
+const (
+	// PayloadColumns mengirim tabel per kolom, {"Name": ["John", "Jane"]},
+	// seperti yang diharapkan TAPAS. Ini bentuk default.
+	PayloadColumns PayloadFormat = "columns"
+	// PayloadRecords mengirim tabel per baris, [{"Name": "John"}, {"Name":
+	// "Jane"}], untuk endpoint lain yang mengharapkan bentuk records.
+	PayloadRecords PayloadFormat = "records"
+)
+
+// ParsePayloadFormat mengubah nama format, misal nilai flag, menjadi
+// PayloadFormat. String kosong berarti PayloadColumns.
+func ParsePayloadFormat(s string) (PayloadFormat, error) {
+	switch f := PayloadFormat(s); f {
+	case "", PayloadColumns:
+		return PayloadColumns, nil
+	case PayloadRecords:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown payload format %q (want %q or %q)", s, PayloadColumns, PayloadRecords)
+	}
+}
+
+// orderedColumns mengembalikan nama kolom table dengan urutan order lebih
+// dulu, lalu kolom yang tidak disebut di order secara urut nama. Nama di
+// order yang tidak ada di table, atau yang disebut dua kali, dilewati.
+func orderedColumns(table map[string][]string, order []string) []string {
+	columns := make([]string, 0, len(table))
+	seen := make(map[string]bool, len(table))
+	for _, column := range order {
+		if _, ok := table[column]; ok && !seen[column] {
+			seen[column] = true
+			columns = append(columns, column)
+		}
+	}
+
+	var rest []string
+	for column := range table {
+		if !seen[column] {
+			rest = append(rest, column)
+		}
+	}
+	sort.Strings(rest)
+	return append(columns, rest...)
+}
+
+// marshalInputs mengubah inputs menjadi body JSON sesuai format, dengan
+// kolom tabel ditulis sesuai order (lihat orderedColumns). encoding/json
+// selalu mengurutkan key map, padahal TAPAS membaca kolom sesuai urutan di
+// body, sehingga objek ditulis sendiri di sini.
+func marshalInputs(inputs Inputs, format PayloadFormat, order []string) ([]byte, error) {
+	f, err := ParsePayloadFormat(string(format))
+	if err != nil {
+		return nil, err
+	}
+	t := Table{Columns: orderedColumns(inputs.Table, order), Data: inputs.Table}
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"table":`)
+	if f == PayloadColumns {
+		// {"Name": ["John", "Jane"], ...}
+		fields := make([]interface{}, len(t.Columns))
+		for i, column := range t.Columns {
+			fields[i] = t.Data[column]
+		}
+		if err := writeObject(&buf, t.Columns, fields); err != nil {
+			return nil, err
+		}
+	} else {
+		// [{"Name": "John", ...}, ...]
+		buf.WriteByte('[')
+		for i := 0; i < rowCount(t); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			row := t.row(i)
+			fields := make([]interface{}, len(row))
+			for j, value := range row {
+				fields[j] = value
+			}
+			if err := writeObject(&buf, t.Columns, fields); err != nil {
+				return nil, err
+			}
+		}
+		buf.WriteByte(']')
+	}
+
+	query, err := json.Marshal(inputs.Query)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteString(`,"query":`)
+	buf.Write(query)
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// writeObject menulis objek JSON dengan key keys dan nilai values ke buf
+// sesuai urutan keys.
+func writeObject(buf *bytes.Buffer, keys []string, values []interface{}) error {
+	buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return nil
+}
@@ -0,0 +1,105 @@
+package tableqa_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("payloadFormat", func() {
+	payload := tableqa.Inputs{
+		Table: map[string][]string{"Name": {"John", "Jane"}, "Age": {"30", "25"}},
+		Query: "What is the age of John?",
+	}
+
+	// sentBody mengirim payload dengan format dan opsi lain lalu mengembalikan
+	// body permintaannya
+	sentBody := func(format tableqa.PayloadFormat, opts ...tableqa.Option) string {
+		var body []byte
+		connector := tableqa.NewAIModelConnector(append(opts,
+			tableqa.WithPayloadFormat(format),
+			tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+				var err error
+				body, err = ioutil.ReadAll(req.Body)
+				Expect(err).ShouldNot(HaveOccurred())
+				return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{"answer": "30"}`))}, nil
+			})),
+		)...)
+		_, err := connector.ConnectAIModel(context.Background(), payload, "token")
+		Expect(err).ShouldNot(HaveOccurred())
+		return string(body)
+	}
+
+	DescribeTable("sends the table in columns form",
+		func(format tableqa.PayloadFormat) {
+			Expect(sentBody(format)).Should(MatchJSON(`{
+				"table": {"Name": ["John", "Jane"], "Age": ["30", "25"]},
+				"query": "What is the age of John?"
+			}`))
+		},
+		Entry("by default", tableqa.PayloadFormat("")),
+		Entry("when asked for", tableqa.PayloadColumns),
+	)
+
+	It("sends the table in records form", func() {
+		Expect(sentBody(tableqa.PayloadRecords)).Should(MatchJSON(`{
+			"table": [{"Name": "John", "Age": "30"}, {"Name": "Jane", "Age": "25"}],
+			"query": "What is the age of John?"
+		}`))
+	})
+
+	DescribeTable("writes the columns in ColumnOrder",
+		func(format tableqa.PayloadFormat, expected string) {
+			Expect(sentBody(format, tableqa.WithColumnOrder([]string{"Name", "Age"}))).Should(Equal(expected))
+		},
+		Entry("columns", tableqa.PayloadColumns,
+			`{"table":{"Name":["John","Jane"],"Age":["30","25"]},"query":"What is the age of John?"}`),
+		Entry("records", tableqa.PayloadRecords,
+			`{"table":[{"Name":"John","Age":"30"},{"Name":"Jane","Age":"25"}],"query":"What is the age of John?"}`),
+	)
+
+	It("writes columns missing from ColumnOrder after it in name order", func() {
+		Expect(sentBody(tableqa.PayloadColumns, tableqa.WithColumnOrder([]string{"Unknown"}))).Should(Equal(
+			`{"table":{"Age":["30","25"],"Name":["John","Jane"]},"query":"What is the age of John?"}`))
+	})
+
+	It("sends the row number column first", func() {
+		table, err := tableqa.AddRowNumbers(tableqa.Table{Columns: []string{"Name", "Age"}, Data: payload.Table})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		var body []byte
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithColumnOrder(table.Columns),
+			tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+				body, err = ioutil.ReadAll(req.Body)
+				Expect(err).ShouldNot(HaveOccurred())
+				return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{"answer": "John"}`))}, nil
+			})),
+		)
+		_, err = connector.ConnectAIModel(context.Background(), tableqa.Inputs{Table: table.Data, Query: "What is in row 1?"}, "token")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(body)).Should(Equal(
+			`{"table":{"row":["1","2"],"Name":["John","Jane"],"Age":["30","25"]},"query":"What is in row 1?"}`))
+	})
+
+	It("rejects an unknown format", func() {
+		connector := tableqa.NewAIModelConnector(tableqa.WithPayloadFormat("rows"))
+		_, err := connector.ConnectAIModel(context.Background(), payload, "token")
+		Expect(err).Should(MatchError(`encoding request: unknown payload format "rows" (want "columns" or "records")`))
+	})
+
+	DescribeTable("parses format names",
+		func(name string, expected tableqa.PayloadFormat) {
+			Expect(tableqa.ParsePayloadFormat(name)).Should(Equal(expected))
+		},
+		Entry("empty", "", tableqa.PayloadColumns),
+		Entry("columns", "columns", tableqa.PayloadColumns),
+		Entry("records", "records", tableqa.PayloadRecords),
+	)
+})
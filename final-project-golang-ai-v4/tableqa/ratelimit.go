@@ -0,0 +1,75 @@
+package tableqa
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// WithRateLimit membatasi connector agar mengirim paling banyak
+// requestsPerMinute permintaan per menit, misal untuk batas free tier
+// Hugging Face. Nilai <= 0 berarti tanpa batas.
+func WithRateLimit(requestsPerMinute int) Option {
+	return func(c *AIModelConnector) {
+		if requestsPerMinute <= 0 {
+			c.Limiter = nil
+			return
+		}
+		c.Limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(requestsPerMinute)), 1)
+	}
+}
+
+// waitTurn menunggu sampai permintaan berikutnya boleh dikirim: setelah masa
+// Retry-After dari respons 429 terakhir lewat, lalu sesuai Limiter jika diset.
+func (c *AIModelConnector) waitTurn(ctx context.Context) error {
+	state := c.shared()
+	state.mu.Lock()
+	notBefore := state.notBefore
+	state.mu.Unlock()
+
+	if wait := time.Until(notBefore); wait > 0 {
+		c.logf("rate limited by the API, waiting %s", wait.Round(time.Millisecond))
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+
+	if c.Limiter == nil {
+		return nil
+	}
+	return c.Limiter.Wait(ctx)
+}
+
+// noteRetryAfter mencatat header Retry-After dari respons 429 sehingga
+// permintaan berikutnya ditahan sampai waktu tersebut.
+func (c *AIModelConnector) noteRetryAfter(resp *http.Response) {
+	wait := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if wait <= 0 {
+		return
+	}
+
+	state := c.shared()
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if until := time.Now().Add(wait); until.After(state.notBefore) {
+		state.notBefore = until
+	}
+}
+
+// parseRetryAfter membaca nilai Retry-After dalam bentuk jumlah detik atau
+// tanggal HTTP, relatif terhadap now. Nilai yang tidak valid menghasilkan nol.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return at.Sub(now)
+	}
+	return 0
+}
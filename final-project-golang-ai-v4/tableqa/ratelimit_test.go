@@ -0,0 +1,108 @@
+package tableqa_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/time/rate"
+)
+
+var _ = Describe("Rate limiting", func() {
+	payload := tableqa.Inputs{
+		Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+		Query: "What is the age of John?",
+	}
+
+	okDoer := func(calls *int) tableqa.HTTPDoer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			*calls++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"answer": "30"}`)),
+			}, nil
+		})
+	}
+
+	It("does not limit requests when no limiter is set", func() {
+		calls := 0
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(okDoer(&calls)))
+		Expect(connector.Limiter).Should(BeNil())
+
+		start := time.Now()
+		for i := 0; i < 5; i++ {
+			_, err := connector.ConnectAIModel(context.Background(), payload, "token")
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+		Expect(calls).Should(Equal(5))
+		Expect(time.Since(start)).Should(BeNumerically("<", 50*time.Millisecond))
+	})
+
+	It("builds a limiter from requests per minute", func() {
+		connector := tableqa.NewAIModelConnector(tableqa.WithRateLimit(30))
+		Expect(connector.Limiter).ShouldNot(BeNil())
+		Expect(connector.Limiter.Limit()).Should(BeNumerically("~", 0.5, 0.001))
+
+		connector = tableqa.NewAIModelConnector(tableqa.WithRateLimit(0))
+		Expect(connector.Limiter).Should(BeNil())
+	})
+
+	It("waits for the limiter before each request", func() {
+		calls := 0
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(okDoer(&calls)))
+		connector.Limiter = rate.NewLimiter(rate.Every(30*time.Millisecond), 1)
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			_, err := connector.ConnectAIModel(context.Background(), payload, "token")
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+		Expect(calls).Should(Equal(3))
+		Expect(time.Since(start)).Should(BeNumerically(">=", 55*time.Millisecond))
+	})
+
+	It("stops waiting when the context is cancelled", func() {
+		calls := 0
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(okDoer(&calls)))
+		connector.Limiter = rate.NewLimiter(rate.Every(time.Hour), 1)
+
+		_, err := connector.ConnectAIModel(context.Background(), payload, "token")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = connector.ConnectAIModel(ctx, payload, "token")
+		Expect(err).Should(HaveOccurred())
+		Expect(calls).Should(Equal(1))
+	})
+
+	It("holds the next request until Retry-After has passed after a 429", func() {
+		calls := 0
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": {"120"}},
+				Body:       ioutil.NopCloser(strings.NewReader(`{"error": "Rate limit reached"}`)),
+			}, nil
+		})))
+
+		_, err := connector.ConnectAIModel(context.Background(), payload, "token")
+		var apiErr *tableqa.APIError
+		Expect(errors.As(err, &apiErr)).Should(BeTrue())
+		Expect(apiErr.StatusCode).Should(Equal(http.StatusTooManyRequests))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = connector.ConnectAIModel(ctx, payload, "token")
+		Expect(errors.Is(err, context.DeadlineExceeded)).Should(BeTrue())
+		Expect(calls).Should(Equal(1))
+	})
+})
@@ -0,0 +1,43 @@
+package tableqa
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+// redacted menggantikan nilai token di log dan pesan error.
+const redacted = "***redacted***"
+
+// RedactHeader mengembalikan salinan h dengan token di header Authorization
+// disamarkan, misal "Bearer ***redacted***". Gunakan ini setiap kali header
+// permintaan akan dicetak ke log.
+func RedactHeader(h http.Header) http.Header {
+	clone := h.Clone()
+	if auth := clone.Get("Authorization"); auth != "" {
+		// Pertahankan skema autentikasi (misal "Bearer") agar log tetap informatif
+		if i := strings.IndexByte(auth, ' '); i >= 0 {
+			clone.Set("Authorization", auth[:i+1]+redacted)
+		} else {
+			clone.Set("Authorization", redacted)
+		}
+	}
+	return clone
+}
+
+// redactToken mengganti setiap kemunculan token di s dengan penanda redacted.
+func redactToken(s, token string) string {
+	if token == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, token, redacted)
+}
+
+// readErrorBody membaca body respons error untuk APIError, menyamarkan token,
+// lalu memotongnya menjadi maxErrorBodyLen byte. Body dibaca len(token) byte
+// lebih dari batas dan disamarkan sebelum dipotong, agar token yang melewati
+// batas potongan tetap utuh saat dicocokkan dan tidak bocor sebagian.
+func readErrorBody(r io.Reader, token string) string {
+	body, _ := io.ReadAll(io.LimitReader(r, int64(maxErrorBodyLen+len(token)+1)))
+	return truncate(redactToken(string(body), token), maxErrorBodyLen)
+}
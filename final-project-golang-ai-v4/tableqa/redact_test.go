@@ -0,0 +1,98 @@
+package tableqa_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Redaction", func() {
+	const token = "hf_supersecrettoken"
+
+	Describe("redactHeader", func() {
+		It("hides the token but keeps the scheme", func() {
+			h := http.Header{}
+			h.Set("Authorization", "Bearer "+token)
+			h.Set("Content-Type", "application/json")
+
+			redacted := tableqa.RedactHeader(h)
+			Expect(redacted.Get("Authorization")).Should(Equal("Bearer ***redacted***"))
+			Expect(redacted.Get("Content-Type")).Should(Equal("application/json"))
+			Expect(h.Get("Authorization")).Should(Equal("Bearer " + token))
+		})
+	})
+
+	It("never writes the token to the verbose log", func() {
+		var logs bytes.Buffer
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithLogger(log.New(&logs, "", 0)),
+			tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader(`{"answer": "30"}`)),
+				}, nil
+			})),
+		)
+
+		_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{
+			Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+			Query: "What is the age of John?",
+		}, token)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(logs.String()).Should(ContainSubstring("Bearer ***redacted***"))
+		Expect(logs.String()).ShouldNot(ContainSubstring(token))
+	})
+
+	It("never returns the token in an error", func() {
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusUnauthorized,
+					Body:       ioutil.NopCloser(strings.NewReader(`{"error": "invalid token ` + token + `"}`)),
+				}, nil
+			})),
+		)
+
+		_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{
+			Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+			Query: "What is the age of John?",
+		}, token)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).ShouldNot(ContainSubstring(token))
+
+		var apiErr *tableqa.APIError
+		Expect(errors.As(err, &apiErr)).Should(BeTrue())
+		Expect(apiErr.Body).Should(ContainSubstring("***redacted***"))
+	})
+
+	It("redacts a token that straddles the error body limit", func() {
+		// Token dimulai beberapa byte sebelum batas 512 byte body error
+		body := strings.Repeat("x", 505) + token + " rejected"
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithHTTPClient(DoerFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusUnauthorized,
+					Body:       ioutil.NopCloser(strings.NewReader(body)),
+				}, nil
+			})),
+		)
+
+		_, err := connector.ConnectAIModel(context.Background(), tableqa.Inputs{
+			Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+			Query: "What is the age of John?",
+		}, token)
+		var apiErr *tableqa.APIError
+		Expect(errors.As(err, &apiErr)).Should(BeTrue())
+		Expect(apiErr.Body).ShouldNot(ContainSubstring(token[:5]))
+		Expect(apiErr.Body).Should(HaveSuffix("..."))
+	})
+})
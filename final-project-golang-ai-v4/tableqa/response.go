@@ -0,0 +1,230 @@
+package tableqa
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Response mengikuti bentuk JSON yang dikembalikan oleh model TAPAS, contoh:
+//
+//	{
+//	    "answer": "SUM > 1.2, 1.2",
+//	    "coordinates": [[0, 3], [1, 3]],
+//	    "cells": ["1.2", "1.2"],
+//	    "aggregator": "SUM"
+//	}
+//
+// Coordinates berisi pasangan [baris, kolom] dari sel yang dipakai untuk menjawab.
+// Sebagian versi model mengirim koordinat sebagai string (misal [["0", "3"]]);
+// keduanya diterima saat decoding.
+//
+// Sebagian varian model juga mengirim "score", yaitu keyakinan model atas
+// jawabannya antara 0 dan 1. Score nol berarti model tidak mengirimnya.
+type Response struct {
+	Answer      string   `json:"answer"`
+	Coordinates [][]int  `json:"coordinates"`
+	Cells       []string `json:"cells"`
+	Aggregator  string   `json:"aggregator"`
+	Score       float64  `json:"score,omitempty"`
+}
+
+// LowConfidence melaporkan apakah model mengirim Score yang lebih kecil dari
+// minScore. Jawaban tanpa Score tidak pernah dianggap kurang yakin karena
+// keyakinannya tidak diketahui.
+func (r Response) LowConfidence(minScore float64) bool {
+	return r.Score > 0 && r.Score < minScore
+}
+
+// Fields meratakan r menjadi pasangan kunci-nilai string (answer,
+// aggregator, cell_count, coordinate_count) untuk logging terstruktur dan
+// analitik. score hanya disertakan jika model mengirimnya.
+func (r Response) Fields() map[string]string {
+	fields := map[string]string{
+		"answer":           r.Answer,
+		"aggregator":       r.Aggregator,
+		"cell_count":       strconv.Itoa(len(r.Cells)),
+		"coordinate_count": strconv.Itoa(len(r.Coordinates)),
+	}
+	if r.Score > 0 {
+		fields["score"] = strconv.FormatFloat(r.Score, 'f', -1, 64)
+	}
+	return fields
+}
+
+// UnmarshalJSON men-decode Response seperti biasa, kecuali Coordinates yang
+// di-decode lewat coordinates agar koordinat berbentuk string juga diterima.
+func (r *Response) UnmarshalJSON(data []byte) error {
+	// plain tidak memiliki method UnmarshalJSON sehingga tidak terjadi rekursi
+	type plain Response
+	aux := struct {
+		*plain
+		Coordinates coordinates `json:"coordinates"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.Coordinates = aux.Coordinates
+	return nil
+}
+
+// coordinates adalah Response.Coordinates yang menerima setiap angka dalam
+// bentuk JSON number (0) maupun string ("0").
+type coordinates [][]int
+
+func (c *coordinates) UnmarshalJSON(data []byte) error {
+	var raw [][]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*c = nil
+		return nil
+	}
+
+	result := make(coordinates, len(raw))
+	for i, pair := range raw {
+		result[i] = make([]int, len(pair))
+		for j, value := range pair {
+			n, err := coordinate(value)
+			if err != nil {
+				return err
+			}
+			result[i][j] = n
+		}
+	}
+	*c = result
+	return nil
+}
+
+// coordinate men-decode satu angka koordinat dari JSON number atau string.
+// Nilai lain dilaporkan sebagai *json.UnmarshalTypeError seperti pada
+// decoding ke [][]int biasa.
+func coordinate(value json.RawMessage) (int, error) {
+	var n int
+	if err := json.Unmarshal(value, &n); err == nil {
+		return n, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+			return n, nil
+		}
+	}
+	return 0, &json.UnmarshalTypeError{Value: string(value), Type: reflect.TypeOf(n), Field: "coordinates"}
+}
+
+// String memformat jawaban untuk ditampilkan ke pengguna. Aggregator (SUM,
+// COUNT, AVERAGE) dan sel yang dipakai model ikut ditampilkan agar terlihat
+// bagaimana jawaban diperoleh. Aggregator NONE atau kosong, yaitu pencarian
+// biasa, tidak ditampilkan; jika sel juga kosong, hanya jawabannya yang
+// dikembalikan.
+func (r Response) String() string {
+	return r.Format(0)
+}
+
+// Format sama dengan String, tetapi hanya menampilkan maxCells sel pertama
+// diikuti "...and K more" jika Cells lebih panjang, agar hasil yang besar
+// tidak memenuhi terminal. maxCells nol atau negatif menampilkan semua sel.
+// Cells di r tidak diubah sehingga output JSON tetap lengkap.
+func (r Response) Format(maxCells int) string {
+	aggregated := r.Aggregator != "" && !strings.EqualFold(r.Aggregator, "NONE")
+	if !aggregated && len(r.Cells) == 0 {
+		return r.Answer
+	}
+
+	var b strings.Builder
+	b.WriteString(r.Answer)
+	if aggregated {
+		fmt.Fprintf(&b, "\nAggregator: %s", r.Aggregator)
+	}
+	if len(r.Cells) > 0 {
+		cells := r.Cells
+		if maxCells > 0 && len(cells) > maxCells {
+			cells = cells[:maxCells]
+		}
+		fmt.Fprintf(&b, "\nCells: %s", strings.Join(cells, ", "))
+		if more := len(r.Cells) - len(cells); more > 0 {
+			fmt.Fprintf(&b, ", ...and %d more", more)
+		}
+	}
+	return b.String()
+}
+
+// DefaultPrecision adalah jumlah angka di belakang koma yang dipakai CLI
+// untuk membulatkan jawaban SUM dan AVERAGE.
+const DefaultPrecision = 2
+
+// Round mengembalikan salinan r dengan Answer dibulatkan ke precision angka
+// di belakang koma, misal "1234.5600000002" menjadi "1234.56", jika
+// Aggregator adalah SUM atau AVERAGE dan Answer berupa angka. Nol di
+// belakang koma dibuang sehingga hasil bulat tetap ditulis tanpa desimal.
+// Jawaban lain, termasuk bentuk "SUM > 1.2, 0.8", dan precision negatif
+// mengembalikan r tanpa perubahan.
+func (r Response) Round(precision int) Response {
+	if precision < 0 {
+		return r
+	}
+	switch strings.ToUpper(r.Aggregator) {
+	case "SUM", "AVERAGE":
+	default:
+		return r
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(r.Answer), 64)
+	if err != nil {
+		return r
+	}
+	answer := strconv.FormatFloat(value, 'f', precision, 64)
+	if strings.Contains(answer, ".") {
+		answer = strings.TrimRight(strings.TrimRight(answer, "0"), ".")
+	}
+	r.Answer = answer
+	return r
+}
+
+// CellRef menunjuk satu sel tabel yang dipakai model untuk menjawab.
+type CellRef struct {
+	// Column adalah nama kolom sel
+	Column string `json:"column"`
+	// RowIndex adalah indeks baris data, mulai dari 0 (header tidak dihitung)
+	RowIndex int `json:"row_index"`
+	// Value adalah isi sel di tabel
+	Value string `json:"value"`
+}
+
+// ResolveCells mengubah setiap pasangan [baris, kolom] di Coordinates menjadi
+// nama kolom dan nilai dari table. Indeks kolom mengacu pada urutan kolom di
+// JSON yang dikirim ke model, yaitu urutan nama kolom secara alfabet karena
+// json.Marshal mengurutkan key map. Koordinat yang tidak berbentuk pasangan
+// atau berada di luar tabel dilewati.
+func (r Response) ResolveCells(table Table) []CellRef {
+	columns := payloadColumns(table.Data)
+
+	refs := make([]CellRef, 0, len(r.Coordinates))
+	for _, coord := range r.Coordinates {
+		if len(coord) != 2 {
+			continue
+		}
+		row, col := coord[0], coord[1]
+		if col < 0 || col >= len(columns) {
+			continue
+		}
+		values := table.Data[columns[col]]
+		if row < 0 || row >= len(values) {
+			continue
+		}
+		refs = append(refs, CellRef{Column: columns[col], RowIndex: row, Value: values[row]})
+	}
+	return refs
+}
+
+// payloadColumns mengembalikan nama kolom table sesuai urutan kemunculannya
+// di body JSON yang dikirim ke model.
+func payloadColumns(table map[string][]string) []string {
+	// json.Marshal menulis key map secara urut
+	return sortedKeys(table)
+}
@@ -0,0 +1,227 @@
+package tableqa_test
+
+import (
+	"encoding/json"
+	"errors"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Response", func() {
+	Describe("response.String", func() {
+		It("prints only the answer for a simple lookup", func() {
+			r := tableqa.Response{Answer: "30"}
+			Expect(r.String()).Should(Equal("30"))
+		})
+
+		DescribeTable("omits the aggregator for a lookup",
+			func(aggregator string) {
+				r := tableqa.Response{Answer: "Refrigerator", Cells: []string{"Refrigerator"}, Aggregator: aggregator}
+				Expect(r.String()).Should(Equal("Refrigerator\nCells: Refrigerator"))
+			},
+			Entry("NONE", "NONE"),
+			Entry("lower-case none", "none"),
+			Entry("empty", ""),
+		)
+
+		DescribeTable("labels aggregated answers",
+			func(aggregator string) {
+				r := tableqa.Response{Answer: "2", Cells: []string{"1.2", "0.8"}, Aggregator: aggregator}
+				Expect(r.String()).Should(Equal("2\nAggregator: " + aggregator + "\nCells: 1.2, 0.8"))
+			},
+			Entry("SUM", "SUM"),
+			Entry("COUNT", "COUNT"),
+			Entry("AVERAGE", "AVERAGE"),
+		)
+
+		It("prints only the answer for NONE without cells", func() {
+			r := tableqa.Response{Answer: "30", Aggregator: "NONE"}
+			Expect(r.String()).Should(Equal("30"))
+		})
+
+		It("includes the aggregator and cells for an aggregated answer", func() {
+			r := tableqa.Response{
+				Answer:      "SUM > 1.2, 0.8",
+				Coordinates: [][]int{{0, 3}, {8, 3}},
+				Cells:       []string{"1.2", "0.8"},
+				Aggregator:  "SUM",
+			}
+			Expect(r.String()).Should(Equal("SUM > 1.2, 0.8\nAggregator: SUM\nCells: 1.2, 0.8"))
+		})
+	})
+
+	Describe("response.Format", func() {
+		r := tableqa.Response{Answer: "Refrigerator", Cells: []string{"A", "B", "C", "D"}}
+
+		DescribeTable("limits the printed cells",
+			func(maxCells int, expected string) {
+				Expect(r.Format(maxCells)).Should(Equal(expected))
+			},
+			Entry("more cells than the limit", 3, "Refrigerator\nCells: A, B, C, ...and 1 more"),
+			Entry("exactly the limit", 4, "Refrigerator\nCells: A, B, C, D"),
+			Entry("no limit", 0, "Refrigerator\nCells: A, B, C, D"),
+		)
+
+		It("does not modify the cells", func() {
+			r.Format(1)
+			Expect(r.Cells).Should(HaveLen(4))
+		})
+	})
+
+	Describe("response.LowConfidence", func() {
+		DescribeTable("compares the score with the threshold",
+			func(score float64, expected bool) {
+				Expect(tableqa.Response{Answer: "TV", Score: score}.LowConfidence(0.5)).Should(Equal(expected))
+			},
+			Entry("below the threshold", 0.2, true),
+			Entry("at the threshold", 0.5, false),
+			Entry("above the threshold", 0.9, false),
+			Entry("no score", 0.0, false),
+		)
+	})
+
+	Describe("response.Fields", func() {
+		It("flattens a populated response", func() {
+			r := tableqa.Response{
+				Answer:      "SUM > 1.2, 0.8",
+				Coordinates: [][]int{{0, 3}, {1, 3}},
+				Cells:       []string{"1.2", "0.8"},
+				Aggregator:  "SUM",
+				Score:       0.87,
+			}
+			Expect(r.Fields()).Should(Equal(map[string]string{
+				"answer":           "SUM > 1.2, 0.8",
+				"aggregator":       "SUM",
+				"cell_count":       "2",
+				"coordinate_count": "2",
+				"score":            "0.87",
+			}))
+		})
+
+		It("flattens an empty response", func() {
+			Expect(tableqa.Response{}.Fields()).Should(Equal(map[string]string{
+				"answer":           "",
+				"aggregator":       "",
+				"cell_count":       "0",
+				"coordinate_count": "0",
+			}))
+		})
+	})
+
+	Describe("response.UnmarshalJSON", func() {
+		It("decodes the score when present", func() {
+			var r tableqa.Response
+			Expect(json.Unmarshal([]byte(`{"answer": "TV", "score": 0.87}`), &r)).Should(Succeed())
+			Expect(r.Score).Should(Equal(0.87))
+
+			var noScore tableqa.Response
+			Expect(json.Unmarshal([]byte(`{"answer": "TV"}`), &noScore)).Should(Succeed())
+			Expect(noScore).Should(Equal(tableqa.Response{Answer: "TV"}))
+		})
+
+		DescribeTable("decodes coordinates as numbers or strings",
+			func(body string) {
+				var r tableqa.Response
+				Expect(json.Unmarshal([]byte(body), &r)).Should(Succeed())
+				Expect(r).Should(Equal(tableqa.Response{
+					Answer:      "SUM > 1.2, 0.8",
+					Coordinates: [][]int{{0, 1}, {1, 1}},
+					Cells:       []string{"1.2", "0.8"},
+					Aggregator:  "SUM",
+				}))
+			},
+			Entry("integers", `{"answer": "SUM > 1.2, 0.8", "coordinates": [[0, 1], [1, 1]], "cells": ["1.2", "0.8"], "aggregator": "SUM"}`),
+			Entry("strings", `{"answer": "SUM > 1.2, 0.8", "coordinates": [["0", "1"], ["1", "1"]], "cells": ["1.2", "0.8"], "aggregator": "SUM"}`),
+			Entry("mixed", `{"answer": "SUM > 1.2, 0.8", "coordinates": [[0, "1"], ["1", 1]], "cells": ["1.2", "0.8"], "aggregator": "SUM"}`),
+		)
+
+		It("leaves missing coordinates nil", func() {
+			var r tableqa.Response
+			Expect(json.Unmarshal([]byte(`{"answer": "John"}`), &r)).Should(Succeed())
+			Expect(r).Should(Equal(tableqa.Response{Answer: "John"}))
+		})
+
+		DescribeTable("rejects coordinates that are not integers",
+			func(body string) {
+				var r tableqa.Response
+				err := json.Unmarshal([]byte(body), &r)
+				var typeErr *json.UnmarshalTypeError
+				Expect(errors.As(err, &typeErr)).Should(BeTrue())
+				Expect(typeErr.Field).Should(Equal("coordinates"))
+			},
+			Entry("non-numeric string", `{"answer": "John", "coordinates": [["a", "1"]]}`),
+			Entry("fraction", `{"answer": "John", "coordinates": [[0.5, 1]]}`),
+			Entry("boolean", `{"answer": "John", "coordinates": [[true, 1]]}`),
+		)
+
+		It("round-trips through json.Marshal", func() {
+			original := tableqa.Response{Answer: "John", Coordinates: [][]int{{0, 0}}, Cells: []string{"John"}, Aggregator: "NONE"}
+			body, err := json.Marshal(original)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			var decoded tableqa.Response
+			Expect(json.Unmarshal(body, &decoded)).Should(Succeed())
+			Expect(decoded).Should(Equal(original))
+		})
+	})
+
+	Describe("response.Round", func() {
+		DescribeTable("rounds numeric SUM and AVERAGE answers",
+			func(response tableqa.Response, precision int, answer string) {
+				Expect(response.Round(precision).Answer).Should(Equal(answer))
+			},
+			Entry("excess decimals", tableqa.Response{Answer: "1234.5600000002", Aggregator: "SUM"}, 2, "1234.56"),
+			Entry("rounding up", tableqa.Response{Answer: "0.666666", Aggregator: "AVERAGE"}, 2, "0.67"),
+			Entry("more places", tableqa.Response{Answer: "0.666666", Aggregator: "AVERAGE"}, 4, "0.6667"),
+			Entry("no places", tableqa.Response{Answer: "2.5001", Aggregator: "SUM"}, 0, "3"),
+			Entry("integer result", tableqa.Response{Answer: "42", Aggregator: "SUM"}, 2, "42"),
+			Entry("integer after rounding", tableqa.Response{Answer: "41.999999", Aggregator: "SUM"}, 2, "42"),
+			Entry("lower-case aggregator", tableqa.Response{Answer: "1.005001", Aggregator: "sum"}, 2, "1.01"),
+		)
+
+		DescribeTable("leaves other answers unchanged",
+			func(response tableqa.Response, precision int) {
+				Expect(response.Round(precision)).Should(Equal(response))
+			},
+			Entry("non-numeric answer", tableqa.Response{Answer: "SUM > 1.2, 0.8", Cells: []string{"1.2", "0.8"}, Aggregator: "SUM"}, 2),
+			Entry("COUNT", tableqa.Response{Answer: "3.14159", Aggregator: "COUNT"}, 2),
+			Entry("lookup", tableqa.Response{Answer: "1.23456", Aggregator: "NONE"}, 2),
+			Entry("negative precision", tableqa.Response{Answer: "1.23456", Aggregator: "SUM"}, -1),
+		)
+	})
+
+	Describe("response.ResolveCells", func() {
+		table := tableqa.Table{
+			Columns: []string{"Date", "Appliance", "Energy_Consumption"},
+			Data: map[string][]string{
+				"Date":               {"2022-01-01", "2022-01-01"},
+				"Appliance":          {"Refrigerator", "TV"},
+				"Energy_Consumption": {"1.2", "0.8"},
+			},
+		}
+
+		It("maps coordinates to column names and values in payload order", func() {
+			// Kolom dikirim urut alfabet: Appliance (0), Date (1), Energy_Consumption (2)
+			r := tableqa.Response{Coordinates: [][]int{{0, 2}, {1, 2}, {1, 0}}}
+			Expect(r.ResolveCells(table)).Should(Equal([]tableqa.CellRef{
+				{Column: "Energy_Consumption", RowIndex: 0, Value: "1.2"},
+				{Column: "Energy_Consumption", RowIndex: 1, Value: "0.8"},
+				{Column: "Appliance", RowIndex: 1, Value: "TV"},
+			}))
+		})
+
+		It("skips coordinates outside the table", func() {
+			r := tableqa.Response{Coordinates: [][]int{{2, 0}, {0, 3}, {-1, 0}, {0, -1}, {0}, {0, 1, 2}, {0, 1}}}
+			Expect(r.ResolveCells(table)).Should(Equal([]tableqa.CellRef{
+				{Column: "Date", RowIndex: 0, Value: "2022-01-01"},
+			}))
+		})
+
+		It("returns no cells without coordinates", func() {
+			Expect(tableqa.Response{Answer: "30"}.ResolveCells(table)).Should(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,57 @@
+package tableqa
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"syscall"
+	"time"
+)
+
+// DefaultRetryBackoff adalah jeda sebelum pengulangan pertama setelah error
+// jaringan. Jeda berikutnya berlipat dua, dibatasi MaxWait.
+const DefaultRetryBackoff = 500 * time.Millisecond
+
+// isRetryable melaporkan apakah err dari client.Do adalah gangguan jaringan
+// sementara, misal koneksi diputus, timeout, atau DNS yang gagal sesaat,
+// sehingga permintaan layak diulang. Error permanen seperti URL yang salah
+// dan context yang dibatalkan tidak diulang.
+func isRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	// Server menutup koneksi sebelum respons selesai dikirim
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	var netErr interface {
+		Timeout() bool
+		Temporary() bool
+	}
+	if errors.As(err, &netErr) {
+		return netErr.Timeout() || netErr.Temporary()
+	}
+	return false
+}
+
+// retryBackoff mengembalikan jeda sebelum pengulangan ke-(attempt+1) setelah
+// error jaringan: DefaultRetryBackoff yang berlipat dua setiap kali, dibatasi
+// MaxWait, lalu diacak antara setengah dan penuh agar banyak client yang
+// gagal bersamaan tidak mengulang pada saat yang sama.
+func (c *AIModelConnector) retryBackoff(attempt int) time.Duration {
+	maxWait := c.maxWait()
+	wait := DefaultRetryBackoff
+	for i := 0; i < attempt && wait < maxWait; i++ {
+		wait *= 2
+	}
+	if wait > maxWait {
+		wait = maxWait
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
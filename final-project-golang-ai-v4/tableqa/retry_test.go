@@ -0,0 +1,97 @@
+package tableqa_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// temporaryError meniru error jaringan sementara seperti koneksi yang diputus.
+type temporaryError struct{}
+
+func (temporaryError) Error() string   { return "connection reset by peer" }
+func (temporaryError) Timeout() bool   { return false }
+func (temporaryError) Temporary() bool { return true }
+
+var _ = Describe("network retries", func() {
+	payload := tableqa.Inputs{
+		Table: map[string][]string{"Name": {"John"}, "Age": {"30"}},
+		Query: "What is the age of John?",
+	}
+
+	// failingDoer gagal dengan err sebanyak failures kali lalu berhasil
+	failingDoer := func(failures int, err error, calls *int) tableqa.HTTPDoer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			*calls++
+			if *calls <= failures {
+				return nil, &url.Error{Op: "Post", URL: req.URL.String(), Err: err}
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"answer": "30", "coordinates": [[0, 1]], "cells": ["30"], "aggregator": "NONE"}`)),
+			}, nil
+		})
+	}
+
+	DescribeTable("retries temporary network errors until the request succeeds",
+		func(err error) {
+			calls := 0
+			connector := tableqa.NewAIModelConnector(
+				tableqa.WithRetries(3, time.Millisecond),
+				tableqa.WithHTTPClient(failingDoer(2, err, &calls)),
+			)
+
+			result, metrics, err := connector.ConnectAIModelWithMetrics(context.Background(), payload, "token")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.Answer).Should(Equal("30"))
+			Expect(calls).Should(Equal(3))
+			Expect(metrics.Attempts).Should(Equal(3))
+		},
+		Entry("a temporary error", temporaryError{}),
+		Entry("a temporary DNS failure", &net.DNSError{Err: "server misbehaving", Name: "api-inference.huggingface.co", IsTemporary: true}),
+		Entry("a timeout", &net.DNSError{Err: "i/o timeout", Name: "api-inference.huggingface.co", IsTimeout: true}),
+	)
+
+	It("gives up once MaxRetries is exhausted", func() {
+		calls := 0
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithRetries(1, time.Millisecond),
+			tableqa.WithHTTPClient(failingDoer(5, temporaryError{}, &calls)),
+		)
+
+		_, err := connector.ConnectAIModel(context.Background(), payload, "token")
+		Expect(err).Should(MatchError(ContainSubstring("connection reset by peer")))
+		Expect(calls).Should(Equal(2))
+	})
+
+	It("does not retry a permanent error", func() {
+		calls := 0
+		connector := tableqa.NewAIModelConnector(
+			tableqa.WithRetries(3, time.Millisecond),
+			tableqa.WithHTTPClient(failingDoer(1, errors.New("unsupported protocol scheme"), &calls)),
+		)
+
+		_, err := connector.ConnectAIModel(context.Background(), payload, "token")
+		Expect(err).Should(MatchError(ContainSubstring("unsupported protocol scheme")))
+		Expect(calls).Should(Equal(1))
+	})
+
+	It("does not retry without MaxRetries", func() {
+		calls := 0
+		connector := tableqa.NewAIModelConnector(tableqa.WithHTTPClient(failingDoer(1, temporaryError{}, &calls)))
+
+		_, err := connector.ConnectAIModel(context.Background(), payload, "token")
+		Expect(err).Should(HaveOccurred())
+		Expect(calls).Should(Equal(1))
+	})
+})
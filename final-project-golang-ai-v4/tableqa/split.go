@@ -0,0 +1,49 @@
+package tableqa
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTableIndex dikembalikan oleh TableAt ketika index di luar jumlah tabel.
+var ErrTableIndex = errors.New("table index out of range")
+
+// SplitTables memecah data yang berisi beberapa tabel CSV yang dipisahkan
+// baris kosong, seperti hasil export beberapa worksheet Excel ke satu file.
+// Baris yang hanya berisi spasi atau pemisah kolom (",,,") juga dianggap
+// kosong. Setiap tabel dikembalikan apa adanya, termasuk baris header-nya,
+// dan baris kosong berturut-turut tidak menghasilkan tabel kosong. Nilai
+// dalam tanda kutip yang memuat baris kosong ikut terpotong, jadi fungsi ini
+// hanya untuk export yang sederhana.
+func SplitTables(data string) []string {
+	var tables []string
+	var current []string
+	flush := func() {
+		if len(current) > 0 {
+			tables = append(tables, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if strings.Trim(line, " \t,;") == "" {
+			flush()
+			continue
+		}
+		current = append(current, line)
+	}
+	flush()
+	return tables
+}
+
+// TableAt mengembalikan tabel ke-index (mulai dari 0) hasil SplitTables(data).
+// Index di luar jangkauan dikembalikan sebagai error yang membungkus
+// ErrTableIndex beserta jumlah tabel yang ada.
+func TableAt(data string, index int) (string, error) {
+	tables := SplitTables(data)
+	if index < 0 || index >= len(tables) {
+		return "", fmt.Errorf("%w: %d (found %d tables)", ErrTableIndex, index, len(tables))
+	}
+	return tables[index], nil
+}
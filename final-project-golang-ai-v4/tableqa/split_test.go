@@ -0,0 +1,56 @@
+package tableqa_test
+
+import (
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("splitTables", func() {
+	data := "Appliance,Energy_Consumption\r\n" +
+		"Refrigerator,1.2\r\n" +
+		"TV,0.8\r\n" +
+		",\r\n" +
+		"\r\n" +
+		"Room,Area\r\n" +
+		"Kitchen,12\r\n"
+
+	It("splits tables on blank lines", func() {
+		Expect(tableqa.SplitTables(data)).Should(Equal([]string{
+			"Appliance,Energy_Consumption\nRefrigerator,1.2\nTV,0.8",
+			"Room,Area\nKitchen,12",
+		}))
+	})
+
+	It("returns a single table unchanged", func() {
+		Expect(tableqa.SplitTables("Name,Age\nJohn,30\n")).Should(Equal([]string{"Name,Age\nJohn,30"}))
+	})
+
+	It("returns no tables for blank data", func() {
+		Expect(tableqa.SplitTables("\n \n,,\n")).Should(BeEmpty())
+	})
+
+	DescribeTable("selects a table by index",
+		func(index int, columns []string) {
+			sub, err := tableqa.TableAt(data, index)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			t, err := tableqa.CsvToTable(sub)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(t.Columns).Should(Equal(columns))
+		},
+		Entry("the first table", 0, []string{"Appliance", "Energy_Consumption"}),
+		Entry("the second table", 1, []string{"Room", "Area"}),
+	)
+
+	DescribeTable("rejects an out-of-range index",
+		func(index int) {
+			_, err := tableqa.TableAt(data, index)
+			Expect(err).Should(MatchError(tableqa.ErrTableIndex))
+			Expect(err).Should(MatchError(ContainSubstring("found 2 tables")))
+		},
+		Entry("past the last table", 2),
+		Entry("a negative index", -1),
+	)
+})
@@ -0,0 +1,13 @@
+package tableqa_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTableqa(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tableqa Suite")
+}
@@ -0,0 +1,47 @@
+package tableqa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// DefaultHubURL adalah alamat Hugging Face Hub, dipakai ValidateToken untuk
+// memeriksa token lewat endpoint whoami.
+const DefaultHubURL = "https://huggingface.co"
+
+// ErrInvalidToken dikembalikan oleh ValidateToken ketika Hugging Face menolak
+// token (status 401).
+var ErrInvalidToken = errors.New("invalid Hugging Face token")
+
+// ValidateToken memeriksa token dengan endpoint whoami di Hugging Face Hub
+// tanpa menjalankan inference. Mengembalikan nil untuk status 200, error yang
+// membungkus ErrInvalidToken untuk status 401, dan *APIError untuk status
+// lainnya. Jika client nil, http.DefaultClient yang digunakan.
+func ValidateToken(ctx context.Context, client HTTPDoer, token string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", DefaultHubURL+"/api/whoami-v2", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("User-Agent", DefaultUserAgent)
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized:
+		return fmt.Errorf("%w: the token was rejected, create a new one at %s/settings/tokens", ErrInvalidToken, DefaultHubURL)
+	default:
+		return &APIError{StatusCode: resp.StatusCode, Body: readErrorBody(resp.Body, token)}
+	}
+}
@@ -0,0 +1,56 @@
+package tableqa_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("validateToken", func() {
+	// stub membalas setiap permintaan dengan status dan body yang diberikan
+	// sambil mencatat permintaan terakhir
+	var lastRequest *http.Request
+	stub := func(status int, body string) tableqa.HTTPDoer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			lastRequest = req
+			return &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+		})
+	}
+
+	It("accepts a valid token", func() {
+		err := tableqa.ValidateToken(context.Background(), stub(http.StatusOK, `{"name": "someone"}`), "hf_valid")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(lastRequest.Method).Should(Equal("GET"))
+		Expect(lastRequest.URL.String()).Should(Equal(tableqa.DefaultHubURL + "/api/whoami-v2"))
+		Expect(lastRequest.Header.Get("Authorization")).Should(Equal("Bearer hf_valid"))
+	})
+
+	It("reports an invalid token", func() {
+		err := tableqa.ValidateToken(context.Background(), stub(http.StatusUnauthorized, `{"error": "Invalid credentials in Authorization header"}`), "hf_invalid")
+		Expect(err).Should(MatchError(tableqa.ErrInvalidToken))
+	})
+
+	It("returns other statuses as an APIError without the token", func() {
+		err := tableqa.ValidateToken(context.Background(), stub(http.StatusInternalServerError, `oops for hf_secret`), "hf_secret")
+
+		var apiErr *tableqa.APIError
+		Expect(errors.As(err, &apiErr)).Should(BeTrue())
+		Expect(apiErr.StatusCode).Should(Equal(http.StatusInternalServerError))
+		Expect(apiErr.Body).ShouldNot(ContainSubstring("hf_secret"))
+		Expect(errors.Is(err, tableqa.ErrInvalidToken)).Should(BeFalse())
+	})
+
+	It("returns transport errors", func() {
+		failing := DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		})
+		Expect(tableqa.ValidateToken(context.Background(), failing, "hf_valid")).Should(MatchError(ContainSubstring("connection refused")))
+	})
+})
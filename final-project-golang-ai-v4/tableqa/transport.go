@@ -0,0 +1,126 @@
+package tableqa
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Nilai default ConnectionPool. http.DefaultTransport hanya menyimpan 2
+// koneksi idle per host, sehingga batch dengan Concurrency lebih dari 2 ke
+// satu host (Hugging Face) terus membuka koneksi TLS baru.
+const (
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 32
+	DefaultIdleConnTimeout     = 2 * time.Minute
+)
+
+// ConnectionPool mengatur berapa banyak koneksi yang disimpan http.Transport
+// untuk dipakai ulang. Field yang nol diganti dengan nilai Default*-nya.
+type ConnectionPool struct {
+	// MaxIdleConns membatasi jumlah koneksi idle ke semua host.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost membatasi jumlah koneksi idle ke satu host;
+	// sebaiknya tidak lebih kecil dari Concurrency.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout adalah lama koneksi idle disimpan sebelum ditutup.
+	IdleConnTimeout time.Duration
+}
+
+// SetConnectionPool mengatur ukuran pool koneksi idle t sesuai pool.
+func SetConnectionPool(t *http.Transport, pool ConnectionPool) {
+	t.MaxIdleConns = DefaultMaxIdleConns
+	if pool.MaxIdleConns > 0 {
+		t.MaxIdleConns = pool.MaxIdleConns
+	}
+	t.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	if pool.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = pool.MaxIdleConnsPerHost
+	}
+	t.IdleConnTimeout = DefaultIdleConnTimeout
+	if pool.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = pool.IdleConnTimeout
+	}
+}
+
+// NewTransport membuat http.Transport untuk memanggil Hugging Face dari balik
+// proxy. Jika proxy kosong, proxy dibaca dari HTTPS_PROXY, HTTP_PROXY, dan
+// NO_PROXY; jika diisi, semua permintaan melewati proxy tersebut. Pool
+// koneksinya memakai nilai default ConnectionPool.
+func NewTransport(proxy string) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+	SetConnectionPool(t, ConnectionPool{})
+
+	if proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %q: %w", proxy, err)
+		}
+		if proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q: want scheme and host, e.g. http://proxy:8080", proxy)
+		}
+		t.Proxy = http.ProxyURL(proxyURL)
+	}
+	return t, nil
+}
+
+// NewHTTPClient membuat http.Client dengan DefaultTimeout yang memakai
+// NewTransport(proxy), siap diberikan ke WithHTTPClient.
+func NewHTTPClient(proxy string) (*http.Client, error) {
+	t, err := NewTransport(proxy)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Timeout: DefaultTimeout, Transport: t}, nil
+}
+
+// LoadCACerts membaca sertifikat CA berformat PEM dari path dan
+// menambahkannya ke sertifikat sistem, sehingga endpoint self-hosted dengan
+// CA internal dipercaya tanpa memutus koneksi ke Hugging Face publik.
+func LoadCACerts(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA certificates: %w", err)
+	}
+
+	// SystemCertPool bisa gagal di beberapa platform; mulai dari pool kosong
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// SetRootCAs membuat t mempercayai sertifikat server yang ditandatangani CA
+// di pool sebagai pengganti CA default. Pool dari LoadCACerts sudah berisi
+// sertifikat sistem, sehingga CA sistem tetap dipercaya. Pengaturan TLS lain
+// di t tetap dipertahankan.
+func SetRootCAs(t *http.Transport, pool *x509.CertPool) {
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	} else {
+		t.TLSClientConfig = t.TLSClientConfig.Clone()
+	}
+	t.TLSClientConfig.RootCAs = pool
+}
+
+// SetInsecureSkipVerify membuat t tidak memeriksa sertifikat server sama
+// sekali, misal untuk server inference tiruan dengan sertifikat self-signed.
+// Hanya untuk pengembangan lokal: koneksi menjadi rentan disadap. Pengaturan
+// TLS lain di t tetap dipertahankan.
+func SetInsecureSkipVerify(t *http.Transport) {
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	} else {
+		t.TLSClientConfig = t.TLSClientConfig.Clone()
+	}
+	t.TLSClientConfig.InsecureSkipVerify = true
+}
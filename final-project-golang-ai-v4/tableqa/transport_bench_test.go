@@ -0,0 +1,64 @@
+package tableqa_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"a21hc3NpZ25tZW50/tableqa"
+)
+
+// benchmarkBatch menjalankan ConnectAIModelBatch dengan 16 permintaan
+// bersamaan ke satu server lokal memakai transport t, lalu melaporkan
+// jumlah koneksi baru per batch. Semakin kecil, semakin banyak koneksi
+// yang dipakai ulang.
+func benchmarkBatch(b *testing.B, t *http.Transport) {
+	var conns int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"answer": "30", "coordinates": [[0, 1]], "cells": ["30"], "aggregator": "NONE"}`))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt64(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+	defer t.CloseIdleConnections()
+
+	connector := tableqa.NewAIModelConnector(
+		tableqa.WithHTTPClient(&http.Client{Transport: t}),
+		tableqa.WithBaseURL(server.URL),
+		tableqa.WithConcurrency(16),
+	)
+	table := map[string][]string{"Name": {"John"}, "Age": {"30"}}
+	queries := make([]string, 64)
+	for i := range queries {
+		queries[i] = "What is the age of John?"
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := connector.ConnectAIModelBatch(context.Background(), table, queries, "token"); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(atomic.LoadInt64(&conns))/float64(b.N), "conns/op")
+}
+
+// BenchmarkBatchDefaultTransport memakai pool bawaan Go (2 koneksi idle per host).
+func BenchmarkBatchDefaultTransport(b *testing.B) {
+	benchmarkBatch(b, http.DefaultTransport.(*http.Transport).Clone())
+}
+
+// BenchmarkBatchTunedTransport memakai pool dari NewTransport.
+func BenchmarkBatchTunedTransport(b *testing.B) {
+	t, err := tableqa.NewTransport("")
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkBatch(b, t)
+}
@@ -0,0 +1,150 @@
+package tableqa_test
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("newTransport", func() {
+	newRequest := func() *http.Request {
+		req, err := http.NewRequest("POST", tableqa.DefaultBaseURL+"/models/"+tableqa.DefaultModel, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		return req
+	}
+
+	It("routes requests through the configured proxy", func() {
+		t, err := tableqa.NewTransport("http://proxy.example.com:8080")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		proxyURL, err := t.Proxy(newRequest())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(proxyURL.String()).Should(Equal("http://proxy.example.com:8080"))
+	})
+
+	It("falls back to the proxy environment variables", func() {
+		t, err := tableqa.NewTransport("")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(t.Proxy).ShouldNot(BeNil())
+	})
+
+	DescribeTable("rejects an invalid proxy URL",
+		func(proxy string) {
+			_, err := tableqa.NewTransport(proxy)
+			Expect(err).Should(HaveOccurred())
+		},
+		Entry("no scheme", "proxy.example.com:8080"),
+		Entry("no host", "http://"),
+		Entry("unparseable", "http://[::1"),
+	)
+
+	It("keeps more idle connections per host than Go's default", func() {
+		t, err := tableqa.NewTransport("")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(t.MaxIdleConns).Should(Equal(tableqa.DefaultMaxIdleConns))
+		Expect(t.MaxIdleConnsPerHost).Should(Equal(tableqa.DefaultMaxIdleConnsPerHost))
+		Expect(t.MaxIdleConnsPerHost).Should(BeNumerically(">", http.DefaultMaxIdleConnsPerHost))
+		Expect(t.IdleConnTimeout).Should(Equal(tableqa.DefaultIdleConnTimeout))
+	})
+
+	It("applies the connection pool given to the connector", func() {
+		connector := tableqa.NewAIModelConnector(tableqa.WithConnectionPool(tableqa.ConnectionPool{
+			MaxIdleConns:        10,
+			MaxIdleConnsPerHost: 8,
+			IdleConnTimeout:     time.Minute,
+		}))
+
+		t := connector.Client.(*http.Client).Transport.(*http.Transport)
+		Expect(t.MaxIdleConns).Should(Equal(10))
+		Expect(t.MaxIdleConnsPerHost).Should(Equal(8))
+		Expect(t.IdleConnTimeout).Should(Equal(time.Minute))
+	})
+
+	It("fills in defaults for an empty connection pool", func() {
+		t := tableqa.NewAIModelConnector().Client.(*http.Client).Transport.(*http.Transport)
+		Expect(t.MaxIdleConns).Should(Equal(tableqa.DefaultMaxIdleConns))
+		Expect(t.MaxIdleConnsPerHost).Should(Equal(tableqa.DefaultMaxIdleConnsPerHost))
+		Expect(t.IdleConnTimeout).Should(Equal(tableqa.DefaultIdleConnTimeout))
+	})
+
+	It("builds a client with the default timeout", func() {
+		client, err := tableqa.NewHTTPClient("http://proxy.example.com:8080")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(client.Timeout).Should(Equal(tableqa.DefaultTimeout))
+		Expect(client.Transport).Should(BeAssignableToTypeOf(&http.Transport{}))
+	})
+})
+
+var _ = Describe("caCerts", func() {
+	var server *httptest.Server
+	BeforeEach(func() {
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"answer": "ok"}`))
+		}))
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	// writePEM menulis data ke file sementara dan mengembalikan path-nya
+	writePEM := func(data []byte) string {
+		path := filepath.Join(GinkgoT().TempDir(), "ca.pem")
+		Expect(os.WriteFile(path, data, 0o600)).Should(Succeed())
+		return path
+	}
+
+	It("trusts a server signed by the loaded CA", func() {
+		path := writePEM(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+		pool, err := tableqa.LoadCACerts(path)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		t, err := tableqa.NewTransport("")
+		Expect(err).ShouldNot(HaveOccurred())
+		tableqa.SetRootCAs(t, pool)
+		Expect(t.TLSClientConfig.RootCAs).Should(BeIdenticalTo(pool))
+
+		resp, err := (&http.Client{Transport: t}).Get(server.URL)
+		Expect(err).ShouldNot(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).Should(Equal(http.StatusOK))
+	})
+
+	It("rejects the same server without the CA", func() {
+		t, err := tableqa.NewTransport("")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, err = (&http.Client{Transport: t}).Get(server.URL)
+		Expect(err).Should(MatchError(ContainSubstring("certificate")))
+	})
+
+	It("accepts the same server when verification is skipped", func() {
+		t, err := tableqa.NewTransport("")
+		Expect(err).ShouldNot(HaveOccurred())
+		tableqa.SetInsecureSkipVerify(t)
+		Expect(t.TLSClientConfig.InsecureSkipVerify).Should(BeTrue())
+
+		resp, err := (&http.Client{Transport: t}).Get(server.URL)
+		Expect(err).ShouldNot(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).Should(Equal(http.StatusOK))
+	})
+
+	It("reports a missing file", func() {
+		_, err := tableqa.LoadCACerts(filepath.Join(GinkgoT().TempDir(), "missing.pem"))
+		Expect(err).Should(MatchError(os.ErrNotExist))
+	})
+
+	It("reports a file without certificates", func() {
+		path := writePEM([]byte("not a certificate"))
+		_, err := tableqa.LoadCACerts(path)
+		Expect(err).Should(MatchError("no PEM certificates found in " + path))
+	})
+})
@@ -0,0 +1,76 @@
+package tableqa
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Tipe kolom yang dikembalikan oleh InferColumnTypes.
+const (
+	TypeInt    = "int"
+	TypeFloat  = "float"
+	TypeBool   = "bool"
+	TypeString = "string"
+)
+
+// InferColumnTypes menebak tipe setiap kolom di table dari nilai-nilainya:
+// TypeInt jika semua nilai bilangan bulat, TypeFloat jika semua nilai angka
+// (campuran bulat dan desimal), TypeBool jika semua nilai true/false, dan
+// TypeString untuk kolom lain. Sel kosong diabaikan; kolom yang seluruhnya
+// kosong dianggap TypeString.
+func InferColumnTypes(table map[string][]string) map[string]string {
+	types := make(map[string]string, len(table))
+	for column, values := range table {
+		types[column] = inferType(values)
+	}
+	return types
+}
+
+// inferType mengembalikan tipe paling sempit yang cocok untuk semua nilai.
+func inferType(values []string) string {
+	// Anggap semua tipe mungkin, lalu coret yang tidak cocok dengan suatu nilai
+	isInt, isFloat, isBool := true, true, true
+	seen := false
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		seen = true
+
+		if isInt {
+			if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+				isInt = false
+			}
+		}
+		if isFloat {
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				isFloat = false
+			}
+		}
+		if isBool && !strings.EqualFold(v, "true") && !strings.EqualFold(v, "false") {
+			isBool = false
+		}
+		if !isInt && !isFloat && !isBool {
+			return TypeString
+		}
+	}
+
+	switch {
+	case !seen:
+		return TypeString
+	case isInt:
+		return TypeInt
+	case isFloat:
+		return TypeFloat
+	case isBool:
+		return TypeBool
+	default:
+		return TypeString
+	}
+}
+
+// IsNumericType melaporkan apakah t adalah tipe angka (TypeInt atau TypeFloat).
+func IsNumericType(t string) bool {
+	return t == TypeInt || t == TypeFloat
+}
@@ -0,0 +1,49 @@
+package tableqa_test
+
+import (
+	"a21hc3NpZ25tZW50/tableqa"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("inferColumnTypes", func() {
+	DescribeTable("infers the type of a single column",
+		func(values []string, expected string) {
+			types := tableqa.InferColumnTypes(map[string][]string{"Column": values})
+			Expect(types).Should(Equal(map[string]string{"Column": expected}))
+		},
+		Entry("integers", []string{"1", "-20", "300"}, tableqa.TypeInt),
+		Entry("floats", []string{"1.5", "0.25", "-3.0"}, tableqa.TypeFloat),
+		Entry("integers mixed with floats", []string{"1", "2.5", "3"}, tableqa.TypeFloat),
+		Entry("exponent notation", []string{"1e3", "2.5E-2"}, tableqa.TypeFloat),
+		Entry("booleans in any case", []string{"true", "False", "TRUE"}, tableqa.TypeBool),
+		Entry("text", []string{"Refrigerator", "TV"}, tableqa.TypeString),
+		Entry("numbers mixed with text", []string{"1", "2", "three"}, tableqa.TypeString),
+		Entry("booleans mixed with numbers", []string{"true", "1"}, tableqa.TypeString),
+		Entry("blank cells are ignored", []string{"1", "", " ", "2"}, tableqa.TypeInt),
+		Entry("values with surrounding spaces", []string{" 1.2 ", "3 "}, tableqa.TypeFloat),
+		Entry("an all-blank column", []string{"", ""}, tableqa.TypeString),
+		Entry("an empty column", []string{}, tableqa.TypeString),
+	)
+
+	It("infers every column of a parsed table", func() {
+		table, err := tableqa.CsvToSlice("Date,Appliance,Energy_Consumption,Room,On\n2022-01-01,Refrigerator,1.2,Kitchen,true\n2022-01-01,TV,1,Living Room,false")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(tableqa.InferColumnTypes(table)).Should(Equal(map[string]string{
+			"Date":               tableqa.TypeString,
+			"Appliance":          tableqa.TypeString,
+			"Energy_Consumption": tableqa.TypeFloat,
+			"Room":               tableqa.TypeString,
+			"On":                 tableqa.TypeBool,
+		}))
+	})
+
+	It("reports numeric types", func() {
+		Expect(tableqa.IsNumericType(tableqa.TypeInt)).Should(BeTrue())
+		Expect(tableqa.IsNumericType(tableqa.TypeFloat)).Should(BeTrue())
+		Expect(tableqa.IsNumericType(tableqa.TypeBool)).Should(BeFalse())
+		Expect(tableqa.IsNumericType(tableqa.TypeString)).Should(BeFalse())
+	})
+})